@@ -2,226 +2,213 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
-	"strings"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/teamserik/online-car-store/internal/api"
+	"github.com/teamserik/online-car-store/internal/auth"
 	"github.com/teamserik/online-car-store/internal/config"
 	"github.com/teamserik/online-car-store/internal/database"
-	"github.com/teamserik/online-car-store/internal/handler"
+	"github.com/teamserik/online-car-store/internal/grpcapi"
+	"github.com/teamserik/online-car-store/internal/health"
+	"github.com/teamserik/online-car-store/internal/logging"
 	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/ratelimit"
 	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/server"
+	"github.com/teamserik/online-car-store/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
-	cfg := config.Load()
-
-	client, err := database.ConnectMongoDB(cfg.MongoURI)
+	cfg, cmd, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := client.Disconnect(ctx); err != nil {
-			log.Printf("Error disconnecting from MongoDB: %v", err)
-		}
-	}()
-
-	carsCollection := database.GetCollection(client, cfg.DatabaseName, "cars")
-	usersCollection := database.GetCollection(client, cfg.DatabaseName, "users")
-	favoritesCollection := database.GetCollection(client, cfg.DatabaseName, "favorites")
-	reviewsCollection := database.GetCollection(client, cfg.DatabaseName, "reviews")
-
-	carRepo := repository.NewMongoCarRepository(carsCollection)
-	userRepo := repository.NewMongoUserRepository(usersCollection)
-	favoriteRepo := repository.NewMongoFavoriteRepository(favoritesCollection, carsCollection)
-	reviewRepo := repository.NewMongoReviewRepository(reviewsCollection)
-
-	mux := http.NewServeMux()
 
-	// Auth endpoints
-	mux.HandleFunc("/api/auth/register", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			handler.Register(userRepo)(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if cmd.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
 		}
-	})
+		return
+	}
 
-	mux.HandleFunc("/api/auth/login", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			handler.Login(userRepo)(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	slog.SetDefault(logging.New(cfg.Log, os.Stderr))
 
-	mux.HandleFunc("/api/auth/profile", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			middleware.AuthMiddleware(handler.GetProfile(userRepo))(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	// `api migrate up|down|status` manages the schema instead of serving
+	if len(cmd.Args) > 0 && cmd.Args[0] == "migrate" {
+		if err := runMigrateCommand(cfg, cmd.Args[1:]); err != nil {
+			log.Fatal(err)
 		}
-	})
+		return
+	}
+	if len(cmd.Args) > 0 {
+		log.Fatalf("unknown command %q", cmd.Args[0])
+	}
 
-	// Car endpoints
-	mux.HandleFunc("/api/cars", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/api/cars" {
-			http.NotFound(w, r)
-			return
-		}
+	if err := serve(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
 
-		switch r.Method {
-		case http.MethodGet:
-			handler.ListCars(carRepo)(w, r)
-		case http.MethodPost:
-			middleware.AuthMiddleware(handler.CreateCar(carRepo))(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// serve runs the API until SIGINT or SIGTERM. It returns instead of exiting
+// so that the MongoDB client is always disconnected after the server drains.
+func serve(cfg *config.Config) error {
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("flushing traces", "error", err)
 		}
-	})
+	}()
 
-	mux.HandleFunc("/api/cars/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/cars" || r.URL.Path == "/api/cars/" {
-			http.Redirect(w, r, "/api/cars", http.StatusMovedPermanently)
-			return
+	client, err := database.ConnectMongoDB(cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Disconnect(ctx); err != nil {
+			slog.Error("disconnecting from MongoDB", "error", err)
 		}
+		slog.Info("disconnected from MongoDB")
+	}()
 
-		// Извлекаем ID из пути
-		path := strings.TrimPrefix(r.URL.Path, "/api/cars/")
-
-		// Проверяем если это запрос отзывов
-		if strings.HasSuffix(path, "/reviews") {
-			carID := strings.TrimSuffix(path, "/reviews")
-
-			switch r.Method {
-			case http.MethodPost:
-				// Добавляем car_id в контекст запроса для handler
-				r.URL.RawQuery = "car_id=" + carID
-				middleware.AuthMiddleware(handler.CreateReview(reviewRepo, userRepo))(w, r)
-			case http.MethodGet:
-				r.URL.RawQuery = "car_id=" + carID
-				handler.GetCarReviews(reviewRepo)(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-			return
-		}
+	if err := startupMigrations(client.Database(cfg.Mongo.Database), cfg.Mongo.MigrateOnStart); err != nil {
+		return err
+	}
 
-		// Обычные операции с машинами
-		switch r.Method {
-		case http.MethodGet:
-			handler.GetCar(carRepo)(w, r)
-		case http.MethodPut:
-			middleware.AuthMiddleware(handler.UpdateCar(carRepo))(w, r)
-		case http.MethodDelete:
-			middleware.AuthMiddleware(handler.DeleteCar(carRepo))(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	carsCollection := database.GetCollection(client, cfg.Mongo.Database, "cars")
+	usersCollection := database.GetCollection(client, cfg.Mongo.Database, "users")
+	favoritesCollection := database.GetCollection(client, cfg.Mongo.Database, "favorites")
+	favoriteCollectionsCollection := database.GetCollection(client, cfg.Mongo.Database, "favorite_collections")
+	reviewsCollection := database.GetCollection(client, cfg.Mongo.Database, "reviews")
+	garageCollection := database.GetCollection(client, cfg.Mongo.Database, "garage")
+	reviewVotesCollection := database.GetCollection(client, cfg.Mongo.Database, "review_votes")
+	notificationsCollection := database.GetCollection(client, cfg.Mongo.Database, "notifications")
 
-	// Favorites endpoints
-	mux.HandleFunc("/api/favorites", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/api/favorites" {
-			http.NotFound(w, r)
-			return
-		}
+	carRepo := repository.NewMongoCarRepository(carsCollection)
+	userRepo := repository.NewMongoUserRepository(usersCollection)
+	favoriteRepo := repository.NewMongoFavoriteRepository(favoritesCollection, carsCollection, favoriteCollectionsCollection)
+	reviewRepo := repository.NewMongoReviewRepository(reviewsCollection, carsCollection, reviewVotesCollection)
+	notificationRepo := repository.NewMongoNotificationRepository(notificationsCollection)
+	garageRepo := repository.NewMongoGarageRepository(garageCollection, carsCollection)
 
-		switch r.Method {
-		case http.MethodGet:
-			middleware.AuthMiddleware(handler.GetFavorites(favoriteRepo))(w, r)
-		case http.MethodPost:
-			middleware.AuthMiddleware(handler.AddToFavorites(favoriteRepo))(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	authManager := auth.NewManager(cfg.Auth)
 
-	mux.HandleFunc("/api/favorites/count", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			middleware.AuthMiddleware(handler.GetFavoritesCount(favoriteRepo))(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.Register("mongo", database.Ping(client))
+	checker.RegisterDetail("mongo", func(ctx context.Context) (any, error) {
+		return database.DescribeTopology(ctx, client)
 	})
 
-	mux.HandleFunc("/api/favorites/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/favorites" || r.URL.Path == "/api/favorites/" {
-			http.Redirect(w, r, "/api/favorites", http.StatusMovedPermanently)
-			return
-		}
-
-		if strings.HasPrefix(r.URL.Path, "/api/favorites/count") {
-			return // Уже обработано выше
-		}
-
-		if r.Method == http.MethodDelete {
-			middleware.AuthMiddleware(handler.RemoveFromFavorites(favoriteRepo))(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
+	routes := api.Routes(api.Deps{
+		Cars:          carRepo,
+		Users:         userRepo,
+		Favorites:     favoriteRepo,
+		Reviews:       reviewRepo,
+		Notifications: notificationRepo,
+		Garage:        garageRepo,
+		Auth:          authManager,
+		Health:        checker,
+		Features:      cfg.Features,
+		Metrics:       cfg.Metrics,
+		GraphQL:       cfg.GraphQL,
+		Static:        http.FileServer(http.Dir("./static")),
 	})
-
-	// Reviews endpoints
-	mux.HandleFunc("/api/reviews", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/api/reviews" {
-			http.NotFound(w, r)
-			return
-		}
-
-		switch r.Method {
-		case http.MethodGet:
-			handler.GetCarReviews(reviewRepo)(w, r)
-		case http.MethodPost:
-			middleware.AuthMiddleware(handler.CreateReview(reviewRepo, userRepo))(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	mux := routes.Mux()
+
+	// Metrics, AccessLog and TraceRoute read the matched route, so they wrap the router directly
+	var routed http.Handler = middleware.TraceRoute(routes)
+	var limitStore *ratelimit.MemoryStore
+	if cfg.RateLimit.Enabled {
+		var store ratelimit.Store
+		if cfg.RateLimit.Store == "mongo" {
+			store = ratelimit.NewMongoStore(database.GetCollection(client, cfg.Mongo.Database, "rate_limits"))
+		} else {
+			limitStore = ratelimit.NewMemoryStore()
+			store = limitStore
 		}
-	})
-
-	mux.HandleFunc("/api/reviews/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/reviews" || r.URL.Path == "/api/reviews/" {
-			http.Redirect(w, r, "/api/reviews", http.StatusMovedPermanently)
-			return
+		limit, err := middleware.RateLimit(cfg.RateLimit, store, authManager, mux)
+		if err != nil {
+			return err
 		}
+		routed = limit(routed)
+	}
+	routed = middleware.Metrics(routed)
+	if cfg.Log.AccessLog {
+		routed = middleware.AccessLog(routed)
+	}
+	routed = middleware.Deadline(cfg.Deadlines, mux)(routed)
+	traced := otelhttp.NewHandler(middleware.RequestID(routed), "http",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/healthz" && r.URL.Path != "/readyz" && r.URL.Path != cfg.Metrics.Path
+		}),
+	)
+	handler := middleware.CORS(cfg.CORS, mux)(traced)
+	if cfg.API.LegacyAliases {
+		// Outside everything that looks up the route, so aliases resolve to /api/v1 routes
+		legacy, err := middleware.LegacyAPI(cfg.API, mux)
+		if err != nil {
+			return err
+		}
+		handler = legacy(handler)
+	}
+	handler = middleware.SecurityHeaders(cfg.Security)(handler)
 
-		switch r.Method {
-		case http.MethodPut:
-			middleware.AuthMiddleware(handler.UpdateReview(reviewRepo))(w, r)
-		case http.MethodDelete:
-			middleware.AuthMiddleware(handler.DeleteReview(reviewRepo))(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
+	srv, err := server.New(cfg.Server, handler)
+	if err != nil {
+		return err
+	}
+	srv.OnShutdown(checker.ShuttingDown)
+	if limitStore != nil {
+		srv.Go("ratelimit-cleanup", func(ctx context.Context) { limitStore.Cleanup(ctx, time.Minute) })
+	}
+	if cfg.GRPC.Enabled {
+		var creds credentials.TransportCredentials
+		if tlsConfig := srv.TLSConfig(); tlsConfig != nil {
+			creds = credentials.NewTLS(tlsConfig)
+		}
+		grpcServer, err := grpcapi.NewServer(grpcapi.Deps{Cars: carRepo, Auth: authManager}, cfg.GRPC, creds)
+		if err != nil {
+			return err
+		}
+		// Listen now so a port in use fails startup instead of the worker
+		lis, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+		if err != nil {
+			return err
+		}
+		srv.Go("grpc", func(ctx context.Context) { grpcapi.Serve(ctx, grpcServer, lis, cfg.Server.ShutdownTimeout) })
+		slog.Info("inventory gRPC service running", "addr", lis.Addr().String())
+	}
+	checker.RegisterDetail("jobs", func(context.Context) (any, error) {
+		return srv.Workers(), nil
 	})
 
-	// Static files
-	fs := http.FileServer(http.Dir("./static"))
-	mux.Handle("/", fs)
-
-	corsHandler := enableCORS(mux)
-
-	addr := ":" + cfg.ServerPort
-	fmt.Printf("Car Store API running on %s\n", addr)
-	fmt.Printf("MongoDB Database: %s\n", cfg.DatabaseName)
-	log.Fatal(http.ListenAndServe(addr, corsHandler))
-}
-
-func enableCORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-		next.ServeHTTP(w, r)
-	})
+	scheme := "http"
+	if srv.TLS() {
+		scheme = "https"
+	}
+	slog.Info("Car Store API running", "addr", srv.Addr(), "scheme", scheme, "database", cfg.Mongo.Database)
+	return srv.Run(ctx)
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/database"
+	"github.com/teamserik/online-car-store/internal/migrate"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const migrateUsage = `usage: api migrate <command>
+
+commands:
+  up [version]   apply pending migrations, up to version if given
+  down [steps]   revert the last applied migrations (default 1)
+  status         list migrations and whether they are applied`
+
+// runMigrateCommand handles the `migrate` subcommand
+func runMigrateCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	arg := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid number %q\n%s", args[1], migrateUsage)
+		}
+		arg = n
+	}
+
+	client, err := database.ConnectMongoDB(cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	runner := migrate.NewRunner(client.Database(cfg.Mongo.Database), migrate.All)
+
+	switch args[0] {
+	case "up":
+		applied, err := runner.Up(ctx, arg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		if arg == 0 {
+			arg = 1
+		}
+		reverted, err := runner.Down(ctx, arg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%4d  %-28s  %s\n", s.Version, state, s.Description)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+	}
+
+	return nil
+}
+
+// startupMigrations applies pending migrations when enabled, and otherwise
+// refuses to start while any are pending
+func startupMigrations(db *mongo.Database, apply bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	runner := migrate.NewRunner(db, migrate.All)
+
+	if apply {
+		applied, err := runner.Up(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("apply migrations: %w", err)
+		}
+		slog.Info("applied migrations", "count", applied)
+		return nil
+	}
+
+	pending, err := runner.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("check migrations: %w", err)
+	}
+	if pending > 0 {
+		// The unique indexes guard against duplicate users and favorites, so
+		// serving without them would let concurrent requests create duplicates
+		return fmt.Errorf("%d pending migration(s); run `api migrate up` or start with -migrate", pending)
+	}
+	return nil
+}
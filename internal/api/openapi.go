@@ -0,0 +1,187 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/teamserik/online-car-store/internal/gql"
+	"github.com/teamserik/online-car-store/internal/health"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/openapi"
+	"github.com/teamserik/online-car-store/internal/router"
+)
+
+// Bodies handlers write as maps, named for the document
+
+// MessageResponse confirms an action
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// ShareResponse is the link to a shared collection
+type ShareResponse struct {
+	ShareToken string `json:"share_token"`
+	URL        string `json:"url"`
+}
+
+// CountResponse is the number of favorites
+type CountResponse struct {
+	Count int64 `json:"count"`
+}
+
+// GarageImportResponse is the garage after an import
+type GarageImportResponse struct {
+	Imported int                       `json:"imported"`
+	Garage   []model.GarageItemWithCar `json:"garage"`
+}
+
+// GraphQLRequest is a GraphQL request body
+type GraphQLRequest gql.Request
+
+// GraphQLResponse is a GraphQL result; data is null when the request failed before running
+type GraphQLResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError is one entry of a GraphQL result's errors
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// HealthResponse reports liveness
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadyResponse reports readiness and the checks behind it
+type ReadyResponse struct {
+	Status string          `json:"status"`
+	Checks []health.Result `json:"checks"`
+}
+
+var (
+	pageParams = []openapi.Param{
+		{Name: "page", Type: "integer", Description: "1-based page number"},
+		{Name: "limit", Type: "integer", Description: "Items per page"},
+	}
+	ifMatch = openapi.Param{Name: "If-Match", Description: "ETag from a previous read; the write fails with 412 if the resource changed since"}
+)
+
+func withSort(sorts ...string) []openapi.Param {
+	return append(pageParams[:len(pageParams):len(pageParams)], openapi.Param{Name: "sort", Enum: sorts})
+}
+
+// endpoints documents every route, keyed by ServeMux pattern
+func endpoints(d Deps) map[string]openapi.Endpoint {
+	favoriteSorts := withSort(model.FavoriteSortNewest, model.FavoriteSortOldest, model.FavoriteSortPriceAsc, model.FavoriteSortPriceDesc)
+	reviewSorts := withSort(model.ReviewSortNewest, model.ReviewSortOldest, model.ReviewSortHighest, model.ReviewSortLowest, model.ReviewSortMostHelpful)
+
+	e := map[string]openapi.Endpoint{
+		"GET /healthz":      {Tag: "health", Summary: "Liveness probe", Response: HealthResponse{}},
+		"GET /readyz":       {Tag: "health", Summary: "Readiness probe", Description: "Answers 503 while a dependency is down or the server is shutting down.", Response: ReadyResponse{}, Errors: []int{http.StatusServiceUnavailable}},
+		"GET /admin/status": {Tag: "health", Summary: "Build, readiness and dependency details", Description: "Admins only.", Auth: true, Response: health.Status{}, Errors: []int{http.StatusForbidden}},
+
+		"GET /api/openapi.json": {Tag: "docs", Summary: "This document", ContentType: "application/json"},
+		"GET /docs":             {Tag: "docs", Summary: "Interactive viewer for this document", ContentType: "text/html"},
+
+		"POST /api/v1/auth/register": {Tag: "auth", Summary: "Create an account", Request: model.RegisterInput{}, Status: http.StatusCreated, Response: model.AuthResponse{}, Errors: []int{http.StatusConflict}},
+		"POST /api/v1/auth/login":    {Tag: "auth", Summary: "Get a token", Request: model.LoginInput{}, Response: model.AuthResponse{}},
+		"GET /api/v1/auth/profile":   {Tag: "auth", Summary: "The signed-in user", Auth: true, Response: model.User{}, Errors: []int{http.StatusNotFound}},
+
+		"GET /api/v1/cars": {Tag: "cars", Summary: "List cars", Response: []model.Car{}, Query: []openapi.Param{
+			{Name: "make"}, {Name: "body_type"}, {Name: "fuel_type"}, {Name: "transmission"},
+			{Name: "sort", Enum: []string{model.CarSortNewest, model.CarSortPriceAsc, model.CarSortPriceDesc, model.CarSortRating}},
+		}},
+		"POST /api/v1/cars":                {Tag: "cars", Summary: "List a car for sale", Auth: true, Request: model.CreateCarInput{}, Status: http.StatusCreated, Response: model.Car{}},
+		"GET /api/v1/cars/{id}":            {Tag: "cars", Summary: "Get a car", Description: "Sends an ETag; If-None-Match gives 304 when unchanged.", Response: model.Car{}, Errors: []int{http.StatusNotFound}},
+		"PUT /api/v1/cars/{id}":            {Tag: "cars", Summary: "Replace a car", Auth: true, Headers: []openapi.Param{ifMatch}, Request: model.UpdateCarInput{}, Response: model.Car{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed}},
+		"PATCH /api/v1/cars/{id}":          {Tag: "cars", Summary: "Change some fields of a car", Description: "JSON Merge Patch (RFC 7396).", Auth: true, Headers: []openapi.Param{ifMatch}, Request: model.PatchCarInput{}, Response: model.Car{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed}},
+		"DELETE /api/v1/cars/{id}":         {Tag: "cars", Summary: "Delete a car", Auth: true, Headers: []openapi.Param{ifMatch}, Response: MessageResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed}},
+		"GET /api/v1/cars/{id}/reviews":    {Tag: "reviews", Summary: "Reviews of a car with rating statistics", Query: reviewSorts, Response: model.ReviewsResponse{}},
+		"POST /api/v1/cars/{id}/reviews":   {Tag: "reviews", Summary: "Review a car", Auth: true, Request: model.CreateReviewInput{}, Status: http.StatusCreated, Response: model.Review{}, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+		"GET /api/v1/favorites":            {Tag: "favorites", Summary: "The user's favorites", Auth: true, Query: favoriteSorts, Response: model.FavoritesResponse{}},
+		"POST /api/v1/favorites":           {Tag: "favorites", Summary: "Add a car to favorites", Auth: true, Request: model.AddFavoriteInput{}, Status: http.StatusCreated, Response: MessageResponse{}, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+		"GET /api/v1/favorites/count":      {Tag: "favorites", Summary: "Number of favorites", Auth: true, Response: CountResponse{}},
+		"DELETE /api/v1/favorites/{carId}": {Tag: "favorites", Summary: "Remove a car from favorites", Auth: true, Response: MessageResponse{}, Errors: []int{http.StatusNotFound}},
+		"PUT /api/v1/favorites/{carId}/{field}": {Tag: "favorites", Summary: "Move a favorite or set its note",
+			Description: "field is collection, with a MoveFavoriteInput body, or note, with a FavoriteNoteInput body.",
+			Auth:        true, Request: struct {
+				CollectionID string `json:"collection_id,omitempty" validate:"omitempty,objectid"`
+				Note         string `json:"note,omitempty" validate:"max=1000"`
+			}{}, Response: MessageResponse{}, Errors: []int{http.StatusNotFound}},
+
+		"GET /api/v1/favorites/collections":                   {Tag: "collections", Summary: "The user's collections", Auth: true, Response: []model.FavoriteCollection{}},
+		"POST /api/v1/favorites/collections":                  {Tag: "collections", Summary: "Create a collection", Auth: true, Request: model.CollectionInput{}, Status: http.StatusCreated, Response: model.FavoriteCollection{}, Errors: []int{http.StatusConflict}},
+		"GET /api/v1/favorites/collections/{collectionId}":    {Tag: "collections", Summary: "Favorites in a collection", Auth: true, Query: favoriteSorts, Response: model.FavoritesResponse{}, Errors: []int{http.StatusNotFound}},
+		"PUT /api/v1/favorites/collections/{collectionId}":    {Tag: "collections", Summary: "Rename a collection", Auth: true, Request: model.CollectionInput{}, Response: MessageResponse{}, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+		"DELETE /api/v1/favorites/collections/{collectionId}": {Tag: "collections", Summary: "Delete a collection, keeping its favorites", Auth: true, Response: MessageResponse{}, Errors: []int{http.StatusNotFound}},
+
+		"GET /api/v1/reviews":                              {Tag: "reviews", Summary: "Reviews of a car", Query: append(reviewSorts, openapi.Param{Name: "car_id", Required: true}), Response: model.ReviewsResponse{}},
+		"POST /api/v1/reviews":                             {Tag: "reviews", Summary: "Review the car named in the body", Auth: true, Request: model.CreateReviewInput{}, Status: http.StatusCreated, Response: model.Review{}, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+		"GET /api/v1/reviews/{reviewId}":                   {Tag: "reviews", Summary: "Get a review", Response: model.Review{}, Errors: []int{http.StatusNotFound}},
+		"PUT /api/v1/reviews/{reviewId}":                   {Tag: "reviews", Summary: "Edit your review", Auth: true, Headers: []openapi.Param{ifMatch}, Request: model.UpdateReviewInput{}, Response: model.Review{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed}},
+		"DELETE /api/v1/reviews/{reviewId}":                {Tag: "reviews", Summary: "Delete your review", Auth: true, Headers: []openapi.Param{ifMatch}, Response: MessageResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed}},
+		"POST /api/v1/reviews/{reviewId}/vote":             {Tag: "reviews", Summary: "Vote a review helpful or not", Description: "Sending the same vote again removes it.", Auth: true, Request: model.VoteReviewInput{}, Response: model.Review{}, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+		"GET /api/v1/garage":                               {Tag: "garage", Summary: "The user's garage with live prices", Auth: true, Response: []model.GarageItemWithCar{}},
+		"POST /api/v1/garage":                              {Tag: "garage", Summary: "Save a car to the garage", Auth: true, Request: model.AddGarageInput{}, Status: http.StatusCreated, Response: model.GarageItem{}, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+		"DELETE /api/v1/garage/{carId}":                    {Tag: "garage", Summary: "Remove a car from the garage", Auth: true, Response: MessageResponse{}, Errors: []int{http.StatusNotFound}},
+		"GET /api/v1/notifications":                        {Tag: "notifications", Summary: "The user's notifications", Auth: true, Query: []openapi.Param{{Name: "unread", Type: "boolean"}}, Response: []model.Notification{}},
+		"POST /api/v1/notifications/{notificationId}/read": {Tag: "notifications", Summary: "Mark a notification read", Auth: true, Response: MessageResponse{}, Errors: []int{http.StatusNotFound}},
+	}
+
+	if d.Metrics.Enabled {
+		e["GET "+d.Metrics.Path] = openapi.Endpoint{Tag: "health", Summary: "Prometheus metrics", ContentType: "text/plain"}
+	}
+	if d.GraphQL.Enabled {
+		graphql := "Queries and mutations over the same data as the REST routes; see the schema by introspection. " +
+			"Errors come back in the GraphQL errors array with extensions.code, and queries over the depth or complexity limit are rejected with 400."
+		e["GET /graphql"] = openapi.Endpoint{Tag: "graphql", Summary: "Run a GraphQL query", Description: graphql + " Mutations must be POSTed.", Query: []openapi.Param{
+			{Name: "query", Required: true}, {Name: "operationName"}, {Name: "variables", Description: "JSON object"},
+		}, Response: GraphQLResponse{}}
+		e["POST /graphql"] = openapi.Endpoint{Tag: "graphql", Summary: "Run a GraphQL query or mutation", Description: graphql + " A bearer token is optional; fields that need a user fail with UNAUTHENTICATED without one.", Request: GraphQLRequest{}, Response: GraphQLResponse{}}
+	}
+	if d.Features.SharedCollections {
+		e["POST /api/v1/favorites/collections/{collectionId}/share"] = openapi.Endpoint{Tag: "collections", Summary: "Share a collection by link", Auth: true, Response: ShareResponse{}, Errors: []int{http.StatusNotFound}}
+		e["DELETE /api/v1/favorites/collections/{collectionId}/share"] = openapi.Endpoint{Tag: "collections", Summary: "Stop sharing a collection", Auth: true, Response: MessageResponse{}, Errors: []int{http.StatusNotFound}}
+		e["GET /api/v1/shared/collections/{token}"] = openapi.Endpoint{Tag: "collections", Summary: "A shared collection", Description: "No authentication; leaves out the owner and private notes.", Response: model.SharedCollectionResponse{}, Errors: []int{http.StatusNotFound}}
+	}
+	if d.Features.ReviewReplies {
+		e["POST /api/v1/reviews/{reviewId}/reply"] = openapi.Endpoint{Tag: "reviews", Summary: "Reply to a review as the car's dealer or an admin", Auth: true, Request: model.ReplyReviewInput{}, Status: http.StatusCreated, Response: model.Review{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}}
+		e["DELETE /api/v1/reviews/{reviewId}/reply"] = openapi.Endpoint{Tag: "reviews", Summary: "Delete the reply to a review", Auth: true, Response: MessageResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}}
+	}
+	if d.Features.GarageImport {
+		e["POST /api/v1/garage/import"] = openapi.Endpoint{Tag: "garage", Summary: "Merge a garage kept in the browser", Auth: true, Request: model.ImportGarageInput{}, Response: GarageImportResponse{}}
+	}
+	return e
+}
+
+// document describes the routes registered on r. Routes missing from
+// endpoints are left out, which the route table check reports.
+func document(r *router.Router, d Deps) *openapi.Document {
+	b := openapi.New(openapi.Info{
+		Title:   "Car Store API",
+		Version: "1",
+		Description: "Errors are RFC 7807 problem details. Any /api route may answer 429 with Retry-After when rate limited. " +
+			"The unversioned /api paths are deprecated aliases of /api/v1.",
+	})
+	b.Tag("auth", "Accounts and tokens")
+	b.Tag("cars", "Car listings")
+	b.Tag("reviews", "Reviews, votes and dealer replies")
+	b.Tag("favorites", "Favorite cars")
+	b.Tag("collections", "Named groups of favorites and sharing")
+	b.Tag("garage", "Saved cars with price tracking")
+	b.Tag("notifications", "Activity on the user's content")
+	b.Tag("health", "Probes and metrics")
+	b.Tag("graphql", "The GraphQL endpoint")
+	b.Tag("docs", "This document")
+
+	described := endpoints(d)
+	for _, route := range r.Routes() {
+		if e, ok := described[route.Pattern()]; ok {
+			b.Add(route.Method, route.Path, e)
+		}
+	}
+	return b.Document()
+}
@@ -0,0 +1,44 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/api"
+	"github.com/teamserik/online-car-store/internal/openapi"
+)
+
+// TestOpenAPI checks that the served OpenAPI document describes exactly the
+// registered routes, so a route added without a spec entry fails
+func TestOpenAPI(t *testing.T) {
+	routes := api.Routes(newDeps())
+
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/openapi.json: status %d", rec.Code)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode the document: %v", err)
+	}
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	for _, route := range routes.Routes() {
+		if !documented[route.Pattern()] {
+			t.Errorf("%s is registered but missing from the OpenAPI document", route.Pattern())
+		}
+		delete(documented, route.Pattern())
+	}
+	for pattern := range documented {
+		t.Errorf("%s is documented but not registered", pattern)
+	}
+}
@@ -0,0 +1,159 @@
+// Package api assembles the HTTP API: every route, its handler and the
+// middleware it needs, registered on a router.
+package api
+
+import (
+	"net/http"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/gql"
+	"github.com/teamserik/online-car-store/internal/handler"
+	"github.com/teamserik/online-car-store/internal/health"
+	"github.com/teamserik/online-car-store/internal/metrics"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/openapi"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/router"
+)
+
+// Deps are what the handlers are built from
+type Deps struct {
+	Cars          repository.CarRepository
+	Users         repository.UserRepository
+	Favorites     repository.FavoriteRepository
+	Reviews       repository.ReviewRepository
+	Notifications repository.NotificationRepository
+	Garage        repository.GarageRepository
+
+	Auth     *auth.Manager
+	Health   *health.Checker
+	Features config.Features
+	Metrics  config.Metrics
+	GraphQL  config.GraphQL
+	// Static serves the UI for GET requests no route matches; nil serves nothing
+	Static http.Handler
+}
+
+// Routes registers every route for d
+func Routes(d Deps) *router.Router {
+	r := router.New()
+	requireAuth := router.Middleware(middleware.Authenticate(d.Auth))
+
+	// Health endpoints for the orchestrator
+	r.Get("/healthz", handler.Healthz())
+	r.Get("/readyz", handler.Readyz(d.Health))
+	r.With(requireAuth, middleware.RequireRole("admin")).Get("/admin/status", handler.AdminStatus(d.Health))
+
+	if d.Metrics.Enabled {
+		r.Get(d.Metrics.Path, metrics.Handler().ServeHTTP)
+	}
+
+	for _, version := range versions {
+		v := r.Group("/api/" + version.name)
+		if version.changes != nil {
+			version.changes(v, d, requireAuth)
+		}
+		apiRoutes(v, d, requireAuth)
+	}
+
+	if d.GraphQL.Enabled {
+		graphql := gql.Handler(gql.Deps{Cars: d.Cars, Users: d.Users, Favorites: d.Favorites, Reviews: d.Reviews}, d.GraphQL)
+		optionalAuth := r.With(router.Middleware(middleware.OptionalAuthenticate(d.Auth)))
+		optionalAuth.Get("/graphql", graphql)
+		optionalAuth.Post("/graphql", graphql)
+	}
+
+	// The document is built on first request, once every route is registered
+	r.Get("/api/openapi.json", openapi.Handler(func() *openapi.Document { return document(r, d) }))
+	r.Get("/docs", openapi.Viewer("/api/openapi.json"))
+
+	if d.Static != nil {
+		r.Fallback(d.Static)
+	}
+	return r
+}
+
+// versions are served under /api/{name}. Every version serves the routes in
+// apiRoutes; changes, when set, adjusts them for that version before they are
+// registered, using Replace for a route whose contract changed and the usual
+// methods for routes the version adds. Versions share the repositories in Deps.
+var versions = []struct {
+	name    string
+	changes func(r *router.Router, d Deps, requireAuth router.Middleware)
+}{
+	{name: "v1"},
+}
+
+// apiRoutes registers the JSON API under r
+func apiRoutes(r *router.Router, d Deps, requireAuth router.Middleware) {
+	authed := r.With(requireAuth)
+
+	// Auth
+	r.Post("/auth/register", handler.Register(d.Users, d.Auth))
+	r.Post("/auth/login", handler.Login(d.Users, d.Auth))
+	authed.Get("/auth/profile", handler.GetProfile(d.Users))
+
+	// Cars
+	r.Get("/cars", handler.ListCars(d.Cars))
+	authed.Post("/cars", handler.CreateCar(d.Cars))
+	r.Get("/cars/{id}", handler.GetCar(d.Cars))
+	authed.Put("/cars/{id}", handler.UpdateCar(d.Cars))
+	authed.Patch("/cars/{id}", handler.PatchCar(d.Cars))
+	authed.Delete("/cars/{id}", handler.DeleteCar(d.Cars))
+	r.Get("/cars/{id}/reviews", handler.GetCarReviews(d.Reviews))
+	authed.Post("/cars/{id}/reviews", handler.CreateReview(d.Reviews, d.Users))
+
+	// Favorites
+	favorites := r.Group("/favorites", requireAuth)
+	favorites.Get("", handler.GetFavorites(d.Favorites))
+	favorites.Post("", handler.AddToFavorites(d.Favorites))
+	favorites.Get("/count", handler.GetFavoritesCount(d.Favorites))
+	favorites.Delete("/{carId}", handler.RemoveFromFavorites(d.Favorites))
+	// One pattern for both: /{carId}/collection would collide with /collections/{collectionId}
+	favorites.Put("/{carId}/{field}", router.Switch("field", map[string]http.HandlerFunc{
+		"collection": handler.MoveFavorite(d.Favorites),
+		"note":       handler.UpdateFavoriteNote(d.Favorites),
+	}))
+
+	// Favorite collections
+	collections := favorites.Group("/collections")
+	collections.Get("", handler.GetCollections(d.Favorites))
+	collections.Post("", handler.CreateCollection(d.Favorites))
+	collections.Get("/{collectionId}", handler.GetCollectionFavorites(d.Favorites))
+	collections.Put("/{collectionId}", handler.RenameCollection(d.Favorites))
+	collections.Delete("/{collectionId}", handler.DeleteCollection(d.Favorites))
+	if d.Features.SharedCollections {
+		collections.Post("/{collectionId}/share", handler.ShareCollection(d.Favorites))
+		collections.Delete("/{collectionId}/share", handler.UnshareCollection(d.Favorites))
+
+		// Read-only, no auth
+		r.Get("/shared/collections/{token}", handler.GetSharedCollection(d.Favorites))
+	}
+
+	// Reviews
+	r.Get("/reviews", handler.GetCarReviews(d.Reviews))
+	authed.Post("/reviews", handler.CreateReview(d.Reviews, d.Users))
+	r.Get("/reviews/{reviewId}", handler.GetReview(d.Reviews))
+	authed.Put("/reviews/{reviewId}", handler.UpdateReview(d.Reviews))
+	authed.Delete("/reviews/{reviewId}", handler.DeleteReview(d.Reviews))
+	authed.Post("/reviews/{reviewId}/vote", handler.VoteReview(d.Reviews))
+	if d.Features.ReviewReplies {
+		authed.Post("/reviews/{reviewId}/reply", handler.ReplyToReview(d.Reviews, d.Cars, d.Notifications))
+		authed.Delete("/reviews/{reviewId}/reply", handler.DeleteReviewReply(d.Reviews))
+	}
+
+	// Garage
+	garage := r.Group("/garage", requireAuth)
+	garage.Get("", handler.GetGarage(d.Garage))
+	garage.Post("", handler.AddToGarage(d.Garage, d.Cars))
+	if d.Features.GarageImport {
+		garage.Post("/import", handler.ImportGarage(d.Garage))
+	}
+	garage.Delete("/{carId}", handler.RemoveFromGarage(d.Garage))
+
+	// Notifications
+	notifications := r.Group("/notifications", requireAuth)
+	notifications.Get("", handler.GetNotifications(d.Notifications))
+	notifications.Post("/{notificationId}/read", handler.MarkNotificationRead(d.Notifications))
+}
@@ -0,0 +1,244 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/api"
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/health"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/repository/repositorytest"
+)
+
+// routeEntry is one entry of the expected route table
+type routeEntry struct {
+	// Pattern is the ServeMux pattern, e.g. "GET /api/cars/{id}"
+	Pattern string
+	// Path is a concrete request path the pattern must match
+	Path string
+	// Auth is set when the route requires a bearer token
+	Auth bool
+}
+
+const id = "65f000000000000000000001"
+
+// routeTable is every route the API serves with all features enabled
+var routeTable = []routeEntry{
+	{"GET /healthz", "/healthz", false},
+	{"GET /readyz", "/readyz", false},
+	{"GET /admin/status", "/admin/status", true},
+	{"GET /metrics", "/metrics", false},
+	{"GET /api/openapi.json", "/api/openapi.json", false},
+	{"GET /docs", "/docs", false},
+	{"GET /graphql", "/graphql", false},
+	{"POST /graphql", "/graphql", false},
+
+	{"POST /api/v1/auth/register", "/api/v1/auth/register", false},
+	{"POST /api/v1/auth/login", "/api/v1/auth/login", false},
+	{"GET /api/v1/auth/profile", "/api/v1/auth/profile", true},
+
+	{"GET /api/v1/cars", "/api/v1/cars", false},
+	{"POST /api/v1/cars", "/api/v1/cars", true},
+	{"GET /api/v1/cars/{id}", "/api/v1/cars/" + id, false},
+	{"PUT /api/v1/cars/{id}", "/api/v1/cars/" + id, true},
+	{"PATCH /api/v1/cars/{id}", "/api/v1/cars/" + id, true},
+	{"DELETE /api/v1/cars/{id}", "/api/v1/cars/" + id, true},
+	{"GET /api/v1/cars/{id}/reviews", "/api/v1/cars/" + id + "/reviews", false},
+	{"POST /api/v1/cars/{id}/reviews", "/api/v1/cars/" + id + "/reviews", true},
+
+	{"GET /api/v1/favorites", "/api/v1/favorites", true},
+	{"POST /api/v1/favorites", "/api/v1/favorites", true},
+	{"GET /api/v1/favorites/count", "/api/v1/favorites/count", true},
+	{"DELETE /api/v1/favorites/{carId}", "/api/v1/favorites/" + id, true},
+	{"PUT /api/v1/favorites/{carId}/{field}", "/api/v1/favorites/" + id + "/collection", true},
+	{"PUT /api/v1/favorites/{carId}/{field}", "/api/v1/favorites/" + id + "/note", true},
+
+	{"GET /api/v1/favorites/collections", "/api/v1/favorites/collections", true},
+	{"POST /api/v1/favorites/collections", "/api/v1/favorites/collections", true},
+	{"GET /api/v1/favorites/collections/{collectionId}", "/api/v1/favorites/collections/" + id, true},
+	{"PUT /api/v1/favorites/collections/{collectionId}", "/api/v1/favorites/collections/" + id, true},
+	{"DELETE /api/v1/favorites/collections/{collectionId}", "/api/v1/favorites/collections/" + id, true},
+	{"POST /api/v1/favorites/collections/{collectionId}/share", "/api/v1/favorites/collections/" + id + "/share", true},
+	{"DELETE /api/v1/favorites/collections/{collectionId}/share", "/api/v1/favorites/collections/" + id + "/share", true},
+	{"GET /api/v1/shared/collections/{token}", "/api/v1/shared/collections/abc", false},
+
+	{"GET /api/v1/reviews", "/api/v1/reviews", false},
+	{"POST /api/v1/reviews", "/api/v1/reviews", true},
+	{"GET /api/v1/reviews/{reviewId}", "/api/v1/reviews/" + id, false},
+	{"PUT /api/v1/reviews/{reviewId}", "/api/v1/reviews/" + id, true},
+	{"DELETE /api/v1/reviews/{reviewId}", "/api/v1/reviews/" + id, true},
+	{"POST /api/v1/reviews/{reviewId}/vote", "/api/v1/reviews/" + id + "/vote", true},
+	{"POST /api/v1/reviews/{reviewId}/reply", "/api/v1/reviews/" + id + "/reply", true},
+	{"DELETE /api/v1/reviews/{reviewId}/reply", "/api/v1/reviews/" + id + "/reply", true},
+
+	{"GET /api/v1/garage", "/api/v1/garage", true},
+	{"POST /api/v1/garage", "/api/v1/garage", true},
+	{"POST /api/v1/garage/import", "/api/v1/garage/import", true},
+	{"DELETE /api/v1/garage/{carId}", "/api/v1/garage/" + id, true},
+
+	{"GET /api/v1/notifications", "/api/v1/notifications", true},
+	{"POST /api/v1/notifications/{notificationId}/read", "/api/v1/notifications/" + id + "/read", true},
+}
+
+// newDeps returns API dependencies over in-memory repositories with every
+// feature enabled and a static UI that answers "ui"
+func newDeps() api.Deps {
+	repos := repositorytest.NewMemoryRepos()
+	cfg := config.Default()
+	return api.Deps{
+		Cars:          repos.Cars,
+		Users:         repos.Users,
+		Favorites:     repos.Favorites,
+		Reviews:       repos.Reviews,
+		Notifications: repos.Notifications,
+		Garage:        repos.Garage,
+		Auth:          auth.NewManager(cfg.Auth),
+		Health:        health.NewChecker(time.Second),
+		Features:      cfg.Features,
+		Metrics:       cfg.Metrics,
+		GraphQL:       cfg.GraphQL,
+		Static: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ui"))
+		}),
+	}
+}
+
+// TestRoutes checks the router built by api.Routes against routeTable, and that
+// the legacy /api paths reach the same routes
+func TestRoutes(t *testing.T) {
+	routes := api.Routes(newDeps())
+
+	t.Run("Table", func(t *testing.T) {
+		var registered []string
+		for _, route := range routes.Routes() {
+			registered = append(registered, route.Pattern())
+		}
+		for _, route := range routeTable {
+			if !slices.Contains(registered, route.Pattern) {
+				t.Errorf("%s is in the table but not registered", route.Pattern)
+			}
+		}
+		for _, pattern := range registered {
+			if !slices.ContainsFunc(routeTable, func(r routeEntry) bool { return r.Pattern == pattern }) {
+				t.Errorf("%s is registered but missing from the table", pattern)
+			}
+		}
+	})
+
+	t.Run("Dispatch", func(t *testing.T) {
+		for _, route := range routeTable {
+			method, _, _ := strings.Cut(route.Pattern, " ")
+			req := httptest.NewRequest(method, route.Path, nil)
+			if _, pattern := routes.Mux().Handler(req); pattern != route.Pattern {
+				t.Errorf("%s %s dispatched to %q, want %q", method, route.Path, pattern, route.Pattern)
+			}
+		}
+	})
+
+	t.Run("Auth", func(t *testing.T) {
+		for _, route := range routeTable {
+			if !route.Auth {
+				continue
+			}
+			method, _, _ := strings.Cut(route.Pattern, " ")
+			rec := httptest.NewRecorder()
+			routes.ServeHTTP(rec, httptest.NewRequest(method, route.Path, nil))
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("%s without a token: status %d, want 401", route.Pattern, rec.Code)
+			}
+		}
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		cases := []struct{ method, path, allow string }{
+			{http.MethodDelete, "/api/v1/cars", "GET, HEAD, POST"},
+			{http.MethodPost, "/api/v1/cars/" + id, "GET, HEAD, PUT, PATCH, DELETE"},
+			{http.MethodGet, "/api/v1/auth/login", "POST"},
+			{http.MethodGet, "/api/v1/favorites/" + id + "/note", "PUT"},
+			{http.MethodPut, "/api/v1/garage/import", "POST, DELETE"},
+		}
+		for _, c := range cases {
+			rec := httptest.NewRecorder()
+			routes.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, nil))
+			if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != c.allow {
+				t.Errorf("%s %s: status %d Allow %q, want 405 Allow %q", c.method, c.path, rec.Code, rec.Header().Get("Allow"), c.allow)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("%s %s: Content-Type %q, want a problem", c.method, c.path, ct)
+			}
+		}
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/index.html", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "ui" {
+			t.Errorf("GET /index.html: status %d body %q, want the static UI", rec.Code, rec.Body.String())
+		}
+
+		for _, path := range []string{"/index.html", "/api/unknown"} {
+			rec := httptest.NewRecorder()
+			routes.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("POST %s: status %d, want 404", path, rec.Code)
+			}
+		}
+	})
+
+	t.Run("LegacyAliases", func(t *testing.T) {
+		legacy, err := middleware.LegacyAPI(config.Default().API, routes.Mux())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var matched string
+		h := legacy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			routes.ServeHTTP(w, r)
+			matched = r.Pattern
+		}))
+
+		for _, route := range routeTable {
+			method, _, _ := strings.Cut(route.Pattern, " ")
+			path, ok := strings.CutPrefix(route.Path, "/api/v1/")
+			if !ok {
+				continue
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, "/api/"+path, nil))
+			if matched != route.Pattern {
+				t.Errorf("%s /api/%s was routed to %q, want %q", method, path, matched, route.Pattern)
+			}
+			if rec.Header().Get("Deprecation") == "" || rec.Header().Get("Sunset") == "" {
+				t.Errorf("%s /api/%s: no Deprecation and Sunset headers", method, path)
+			}
+			if link := rec.Header().Get("Link"); link != "<"+route.Path+`>; rel="successor-version"` {
+				t.Errorf("%s /api/%s: Link %q, want the v1 path", method, path, link)
+			}
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/cars", nil))
+		if rec.Header().Get("Deprecation") != "" {
+			t.Error("GET /api/v1/cars is marked deprecated")
+		}
+	})
+
+	t.Run("FeaturesOff", func(t *testing.T) {
+		deps := newDeps()
+		deps.Features = config.Features{}
+		deps.GraphQL.Enabled = false
+		routes := api.Routes(deps)
+
+		for _, path := range []string{"/api/v1/favorites/collections/" + id + "/share", "/api/v1/reviews/" + id + "/reply", "/api/v1/garage/import", "/graphql"} {
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			if _, pattern := routes.Mux().Handler(req); pattern != "" {
+				t.Errorf("POST %s is routed to %q with its feature off", path, pattern)
+			}
+		}
+	})
+}
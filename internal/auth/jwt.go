@@ -5,11 +5,10 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/teamserik/online-car-store/internal/config"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var jwtSecret = []byte("secret-key-change-this-in-production-123456789")
-
 type Claims struct {
 	UserID   string `json:"user_id"`
 	Email    string `json:"email"`
@@ -18,28 +17,43 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID primitive.ObjectID, email, username, role string) (string, error) {
+// Manager issues and validates tokens and hashes passwords with the configured settings
+type Manager struct {
+	secret     []byte
+	tokenTTL   time.Duration
+	bcryptCost int
+}
+
+func NewManager(cfg config.Auth) *Manager {
+	return &Manager{
+		secret:     []byte(cfg.JWTSecret),
+		tokenTTL:   cfg.TokenTTL,
+		bcryptCost: cfg.BcryptCost,
+	}
+}
+
+func (m *Manager) GenerateToken(userID primitive.ObjectID, email, username, role string) (string, error) {
 	claims := Claims{
 		UserID:   userID.Hex(),
 		Email:    email,
 		Username: username,
 		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString(m.secret)
 }
 
-func ValidateToken(tokenString string) (*Claims, error) {
+func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return jwtSecret, nil
+		return m.secret, nil
 	})
 
 	if err != nil {
@@ -1,41 +1,408 @@
+// Package config holds the application's settings. Load layers them, each
+// overriding the previous: built-in defaults, an optional YAML or TOML file,
+// environment variables and command-line flags.
 package config
 
 import (
-	"os"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Config is the complete application configuration
 type Config struct {
-	MongoURI     string
-	DatabaseName string
-	ServerPort   string
-	JWTSecret    string
+	Server    Server    `yaml:"server" toml:"server"`
+	Mongo     Mongo     `yaml:"mongo" toml:"mongo"`
+	Auth      Auth      `yaml:"auth" toml:"auth"`
+	CORS      CORS      `yaml:"cors" toml:"cors"`
+	RateLimit RateLimit `yaml:"rate_limit" toml:"rate_limit"`
+	Features  Features  `yaml:"features" toml:"features"`
+	Health    Health    `yaml:"health" toml:"health"`
+	Metrics   Metrics   `yaml:"metrics" toml:"metrics"`
+	Log       Log       `yaml:"log" toml:"log"`
+	Tracing   Tracing   `yaml:"tracing" toml:"tracing"`
+	Deadlines Deadlines `yaml:"deadlines" toml:"deadlines"`
+	Security  Security  `yaml:"security" toml:"security"`
+	API       API       `yaml:"api" toml:"api"`
+	GraphQL   GraphQL   `yaml:"graphql" toml:"graphql"`
+	GRPC      GRPC      `yaml:"grpc" toml:"grpc"`
 }
 
-func Load() *Config {
-	mongoURI := os.Getenv("MONGO_URI")
-	if mongoURI == "" {
-		mongoURI = "mongodb://localhost:27017"
+// Server configures the HTTP listener
+type Server struct {
+	Port              string        `yaml:"port" toml:"port"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// ShutdownDelay keeps serving with readiness failing before draining starts,
+	// giving load balancers time to notice
+	ShutdownDelay  time.Duration `yaml:"shutdown_delay" toml:"shutdown_delay"`
+	MaxHeaderBytes int           `yaml:"max_header_bytes" toml:"max_header_bytes"`
+	TLS            TLS           `yaml:"tls" toml:"tls"`
+}
+
+// TLS enables HTTPS when both files are set; they are re-read when they change
+type TLS struct {
+	CertFile       string        `yaml:"cert_file" toml:"cert_file"`
+	KeyFile        string        `yaml:"key_file" toml:"key_file"`
+	ReloadInterval time.Duration `yaml:"reload_interval" toml:"reload_interval"`
+}
+
+// Enabled reports whether HTTPS is configured
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// Mongo configures the MongoDB connection
+type Mongo struct {
+	URI            string        `yaml:"uri" toml:"uri" secret:"userinfo"`
+	Database       string        `yaml:"database" toml:"database"`
+	MinPoolSize    uint64        `yaml:"min_pool_size" toml:"min_pool_size"`
+	MaxPoolSize    uint64        `yaml:"max_pool_size" toml:"max_pool_size"`
+	ConnectTimeout time.Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+	MigrateOnStart bool          `yaml:"migrate_on_start" toml:"migrate_on_start"`
+}
+
+// defaultJWTSecret lets tests and tools sign tokens without configuration.
+// Validate rejects it, so a deployment has to set its own.
+const defaultJWTSecret = "secret-key-change-this-in-production-123456789"
+
+// Auth configures token signing and password hashing
+type Auth struct {
+	JWTSecret  string        `yaml:"jwt_secret" toml:"jwt_secret" secret:"true"`
+	TokenTTL   time.Duration `yaml:"token_ttl" toml:"token_ttl"`
+	BcryptCost int           `yaml:"bcrypt_cost" toml:"bcrypt_cost"`
+}
+
+// CORS configures cross-origin access to the API. No origins means the API
+// is only usable from the UI it serves; "*" allows any origin.
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" toml:"allowed_origins"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" toml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" toml:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials" toml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age" toml:"max_age"`
+}
+
+// Security configures the hardening headers sent to browsers
+type Security struct {
+	// ContentSecurityPolicy applies to the static UI; API responses get a deny-all policy
+	ContentSecurityPolicy string `yaml:"content_security_policy" toml:"content_security_policy"`
+	// FrameAncestors is the CSP frame-ancestors source list for every response
+	FrameAncestors        string        `yaml:"frame_ancestors" toml:"frame_ancestors"`
+	ReferrerPolicy        string        `yaml:"referrer_policy" toml:"referrer_policy"`
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age" toml:"hsts_max_age"`
+	HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains" toml:"hsts_include_subdomains"`
+}
+
+// RateLimit configures request throttling. Routes are keyed by ServeMux
+// pattern, e.g. "POST /api/v1/reviews", and take policies like "10/1h"; other
+// /api routes share RequestsPerMinute and Burst.
+type RateLimit struct {
+	Enabled           bool              `yaml:"enabled" toml:"enabled"`
+	Store             string            `yaml:"store" toml:"store"`
+	RequestsPerMinute int               `yaml:"requests_per_minute" toml:"requests_per_minute"`
+	Burst             int               `yaml:"burst" toml:"burst"`
+	Routes            map[string]string `yaml:"routes" toml:"routes"`
+	// TrustedProxies are IPs or CIDRs whose X-Forwarded-For is believed
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+// Features switches optional parts of the API on and off
+type Features struct {
+	SharedCollections bool `yaml:"shared_collections" toml:"shared_collections"`
+	ReviewReplies     bool `yaml:"review_replies" toml:"review_replies"`
+	GarageImport      bool `yaml:"garage_import" toml:"garage_import"`
+}
+
+// Health configures readiness checks
+type Health struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" toml:"check_timeout"`
+}
+
+// Metrics configures the Prometheus endpoint
+type Metrics struct {
+	Enabled bool   `yaml:"enabled" toml:"enabled"`
+	Path    string `yaml:"path" toml:"path"`
+}
+
+// Log configures structured logging
+type Log struct {
+	Level     string `yaml:"level" toml:"level"`
+	Format    string `yaml:"format" toml:"format"`
+	AccessLog bool   `yaml:"access_log" toml:"access_log"`
+}
+
+// Tracing configures OpenTelemetry; the OTLP endpoint comes from the
+// standard OTEL_EXPORTER_OTLP_* environment variables
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// Deadlines bounds how long a request may run, including its database calls.
+// Routes are keyed by ServeMux pattern, e.g. "GET /api/v1/garage", and override Default; zero means no deadline.
+type Deadlines struct {
+	Default time.Duration            `yaml:"default" toml:"default"`
+	Routes  map[string]time.Duration `yaml:"routes" toml:"routes"`
+}
+
+// API configures versioning. Routes live under /api/v1; the unversioned /api
+// paths are aliases kept for old clients, which announce when they go away.
+type API struct {
+	LegacyAliases bool `yaml:"legacy_aliases" toml:"legacy_aliases"`
+	// LegacyDeprecated and LegacySunset are dates like 2027-05-01 for the
+	// Deprecation and Sunset headers of the aliases
+	LegacyDeprecated string `yaml:"legacy_deprecated" toml:"legacy_deprecated"`
+	LegacySunset     string `yaml:"legacy_sunset" toml:"legacy_sunset"`
+}
+
+// LegacyDates parses LegacyDeprecated and LegacySunset
+func (a API) LegacyDates() (deprecated, sunset time.Time, err error) {
+	if deprecated, err = time.Parse(time.DateOnly, a.LegacyDeprecated); err != nil {
+		return
+	}
+	sunset, err = time.Parse(time.DateOnly, a.LegacySunset)
+	return
+}
+
+// GraphQL configures the /graphql endpoint. Queries nested deeper than MaxDepth
+// or costing more than MaxComplexity are rejected before they run; every field
+// costs 1 and a paged list multiplies the cost of its items by the page size.
+type GraphQL struct {
+	Enabled       bool `yaml:"enabled" toml:"enabled"`
+	MaxDepth      int  `yaml:"max_depth" toml:"max_depth"`
+	MaxComplexity int  `yaml:"max_complexity" toml:"max_complexity"`
+}
+
+// GRPC configures the dealer inventory gRPC service, served on its own port
+// next to HTTP and with the same TLS certificate. Dealers authenticate with a
+// JWT or an API key; APIKeys maps the hex SHA-256 digest of each key to the
+// dealer's user ID, so the keys themselves are never stored.
+type GRPC struct {
+	Enabled bool              `yaml:"enabled" toml:"enabled"`
+	Port    string            `yaml:"port" toml:"port"`
+	APIKeys map[string]string `yaml:"api_keys" toml:"api_keys"`
+	// MaxSyncItems caps the cars a single SyncInventory stream may send
+	MaxSyncItems int `yaml:"max_sync_items" toml:"max_sync_items"`
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:              "3000",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			TLS: TLS{
+				ReloadInterval: time.Minute,
+			},
+		},
+		Mongo: Mongo{
+			URI:            "mongodb://localhost:27017",
+			Database:       "car_store",
+			MinPoolSize:    0,
+			MaxPoolSize:    100,
+			ConnectTimeout: 10 * time.Second,
+		},
+		Auth: Auth{
+			JWTSecret:  defaultJWTSecret,
+			TokenTTL:   24 * time.Hour,
+			BcryptCost: 10,
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "If-Match", "If-None-Match", "X-Request-ID", "traceparent", "tracestate"},
+			ExposedHeaders: []string{"ETag", "Location", "X-Request-ID", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Deprecation", "Sunset", "Link"},
+			MaxAge:         10 * time.Minute,
+		},
+		RateLimit: RateLimit{
+			Enabled:           false,
+			Store:             "memory",
+			RequestsPerMinute: 60,
+			Burst:             20,
+			Routes: map[string]string{
+				// Brute force and spam targets
+				"POST /api/v1/auth/register":     "10/1h",
+				"POST /api/v1/auth/login":        "10/1m",
+				"POST /api/v1/reviews":           "20/1h",
+				"POST /api/v1/cars/{id}/reviews": "20/1h",
+			},
+		},
+		Features: Features{
+			SharedCollections: true,
+			ReviewReplies:     true,
+			GarageImport:      true,
+		},
+		Health: Health{
+			CheckTimeout: 2 * time.Second,
+		},
+		Metrics: Metrics{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Log: Log{
+			Level:     "info",
+			Format:    "json",
+			AccessLog: true,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			ServiceName: "car-store-api",
+			SampleRatio: 1,
+		},
+		Deadlines: Deadlines{
+			Default: 5 * time.Second,
+			Routes: map[string]time.Duration{
+				// Lists that join cars into the result, and bulk imports
+				"GET /api/v1/favorites":                            10 * time.Second,
+				"GET /api/v1/favorites/collections/{collectionId}": 10 * time.Second,
+				"GET /api/v1/shared/collections/{token}":           10 * time.Second,
+				"GET /api/v1/reviews":                              10 * time.Second,
+				"GET /api/v1/cars/{id}/reviews":                    10 * time.Second,
+				"GET /api/v1/garage":                               10 * time.Second,
+				"POST /api/v1/garage/import":                       10 * time.Second,
+			},
+		},
+		Security: Security{
+			// The UI uses inline scripts, styles and handlers and shows car photos from anywhere
+			ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; " +
+				"img-src 'self' https: data:; connect-src 'self'; object-src 'none'; base-uri 'self'; form-action 'self'",
+			FrameAncestors: "'none'",
+			ReferrerPolicy: "strict-origin-when-cross-origin",
+			HSTSMaxAge:     180 * 24 * time.Hour,
+		},
+		API: API{
+			LegacyAliases:    true,
+			LegacyDeprecated: "2026-11-01",
+			LegacySunset:     "2027-05-01",
+		},
+		GraphQL: GraphQL{
+			Enabled:       true,
+			MaxDepth:      8,
+			MaxComplexity: 5000,
+		},
+		GRPC: GRPC{
+			Enabled:      false,
+			Port:         "9090",
+			APIKeys:      map[string]string{},
+			MaxSyncItems: 10000,
+		},
 	}
+}
 
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "car_store"
+// Validate reports every setting that is out of range, joined into one error
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+		}
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3000"
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port < 65536, "server.port", "must be a TCP port, got %q", c.Server.Port)
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout", "must not be negative")
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout", "must be positive")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout", "must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout", "must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	check(c.Server.ShutdownDelay >= 0, "server.shutdown_delay", "must not be negative")
+	check(c.Server.MaxHeaderBytes >= 4096, "server.max_header_bytes", "must be at least 4096")
+	check((c.Server.TLS.CertFile == "") == (c.Server.TLS.KeyFile == ""), "server.tls", "cert_file and key_file must be set together")
+	check(!c.Server.TLS.Enabled() || c.Server.TLS.ReloadInterval > 0, "server.tls.reload_interval", "must be positive")
+
+	u, err := url.Parse(c.Mongo.URI)
+	check(err == nil && (u.Scheme == "mongodb" || u.Scheme == "mongodb+srv"), "mongo.uri", "must be a mongodb:// or mongodb+srv:// URI")
+	check(c.Mongo.Database != "", "mongo.database", "is required")
+	check(c.Mongo.MaxPoolSize > 0, "mongo.max_pool_size", "must be positive")
+	check(c.Mongo.MinPoolSize <= c.Mongo.MaxPoolSize, "mongo.min_pool_size", "must not exceed mongo.max_pool_size")
+	check(c.Mongo.ConnectTimeout > 0, "mongo.connect_timeout", "must be positive")
+
+	check(len(c.Auth.JWTSecret) >= 32, "auth.jwt_secret", "must be at least 32 bytes")
+	check(c.Auth.JWTSecret != defaultJWTSecret, "auth.jwt_secret", "must be set; the default is published with the source")
+	check(c.Auth.TokenTTL >= time.Minute, "auth.token_ttl", "must be at least 1m")
+	check(c.Auth.BcryptCost >= 4 && c.Auth.BcryptCost <= 31, "auth.bcrypt_cost", "must be between 4 and 31")
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		check(err == nil && u.Scheme != "" && u.Host != "" && u.Path == "", "cors.allowed_origins", "%q is not an origin like https://example.com", origin)
 	}
+	check(c.CORS.MaxAge >= 0, "cors.max_age", "must not be negative")
+	check(!c.CORS.AllowCredentials || !slices.Contains(c.CORS.AllowedOrigins, "*"), "cors.allow_credentials", "cannot be combined with allowed origin \"*\"")
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-secret-key"
+	check(c.Security.FrameAncestors != "", "security.frame_ancestors", "is required, use 'none' to forbid framing")
+	check(!strings.Contains(c.Security.ContentSecurityPolicy, "frame-ancestors"), "security.content_security_policy", "must not set frame-ancestors, use security.frame_ancestors")
+	check(c.Security.HSTSMaxAge >= 0, "security.hsts_max_age", "must not be negative")
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.RequestsPerMinute > 0, "rate_limit.requests_per_minute", "must be positive")
+		check(c.RateLimit.Burst > 0, "rate_limit.burst", "must be positive")
+		check(c.RateLimit.Store == "memory" || c.RateLimit.Store == "mongo", "rate_limit.store", "must be memory or mongo")
+		for route, spec := range c.RateLimit.Routes {
+			count, period, ok := strings.Cut(spec, "/")
+			n, err := strconv.Atoi(count)
+			d, perr := time.ParseDuration(period)
+			check(ok && err == nil && perr == nil && n > 0 && d > 0, "rate_limit.routes", "%q: %q is not a policy like 10/1h", route, spec)
+		}
+		for _, proxy := range c.RateLimit.TrustedProxies {
+			_, _, err := net.ParseCIDR(proxy)
+			check(err == nil || net.ParseIP(proxy) != nil, "rate_limit.trusted_proxies", "%q is not an IP or CIDR", proxy)
+		}
 	}
 
-	return &Config{
-		MongoURI:     mongoURI,
-		DatabaseName: dbName,
-		ServerPort:   port,
-		JWTSecret:    jwtSecret,
+	check(c.Health.CheckTimeout > 0, "health.check_timeout", "must be positive")
+	check(slices.Contains([]string{"debug", "info", "warn", "error"}, c.Log.Level), "log.level", "must be one of debug, info, warn, error")
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format", "must be json or text")
+	check(slices.Contains([]string{"none", "stdout", "otlp"}, c.Tracing.Exporter), "tracing.exporter", "must be one of none, stdout, otlp")
+	check(c.Tracing.ServiceName != "", "tracing.service_name", "is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+	check(c.Deadlines.Default >= 0, "deadlines.default", "must not be negative")
+	for route, timeout := range c.Deadlines.Routes {
+		check(timeout >= 0, "deadlines.routes", "%q must not be negative", route)
 	}
+	check(!c.Metrics.Enabled || strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path", "must start with /")
+
+	if c.API.LegacyAliases {
+		deprecated, sunset, err := c.API.LegacyDates()
+		check(err == nil, "api.legacy_deprecated", "and api.legacy_sunset must be dates like 2027-05-01")
+		check(err != nil || sunset.After(deprecated), "api.legacy_sunset", "must be after api.legacy_deprecated")
+	}
+
+	if c.GraphQL.Enabled {
+		check(c.GraphQL.MaxDepth > 0, "graphql.max_depth", "must be positive")
+		check(c.GraphQL.MaxComplexity > 0, "graphql.max_complexity", "must be positive")
+	}
+
+	if c.GRPC.Enabled {
+		port, err := strconv.Atoi(c.GRPC.Port)
+		check(err == nil && port > 0 && port < 65536, "grpc.port", "must be a TCP port, got %q", c.GRPC.Port)
+		check(c.GRPC.Port != c.Server.Port, "grpc.port", "must differ from server.port")
+		check(c.GRPC.MaxSyncItems > 0, "grpc.max_sync_items", "must be positive")
+		for digest, dealer := range c.GRPC.APIKeys {
+			sum, err := hex.DecodeString(digest)
+			check(err == nil && len(sum) == sha256.Size, "grpc.api_keys", "%q is not a hex SHA-256 digest", digest)
+			id, err := hex.DecodeString(dealer)
+			check(err == nil && len(id) == 12, "grpc.api_keys", "%q is not a user ID", dealer)
+		}
+	}
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,25 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/config"
+)
+
+func TestValidateJWTSecret(t *testing.T) {
+	cfg := config.Default()
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "auth.jwt_secret") {
+		t.Errorf("Validate with the default secret = %v, want an auth.jwt_secret error", err)
+	}
+
+	cfg.Auth.JWTSecret = strings.Repeat("k", 32)
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate with a 32-byte secret: %v", err)
+	}
+
+	cfg.Auth.JWTSecret = strings.Repeat("k", 31)
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate accepted a 31-byte secret")
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// envAliases maps the variables the service has always read to their settings
+var envAliases = map[string]string{
+	"PORT":       "server.port",
+	"DB_NAME":    "mongo.database",
+	"JWT_SECRET": "auth.jwt_secret",
+}
+
+// flagAliases maps short flags to their settings
+var flagAliases = map[string]string{
+	"migrate": "mongo.migrate_on_start",
+}
+
+// Command is what the command line asked for besides settings
+type Command struct {
+	// PrintConfig asks for the effective configuration to be printed instead of serving
+	PrintConfig bool
+	// Args are the arguments left after flags, such as a subcommand
+	Args []string
+}
+
+// setting is one leaf of Config, addressed by its dotted file key
+type setting struct {
+	key   string
+	value reflect.Value
+	field reflect.StructField
+}
+
+// EnvName returns the environment variable for a dotted key, e.g. SERVER_READ_TIMEOUT
+func EnvName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// FlagName returns the command-line flag for a dotted key, e.g. server.read-timeout
+func FlagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+// Load builds the configuration from defaults, the file named by -config or
+// CONFIG_FILE, the environment and args (usually os.Args[1:]), then validates it
+func Load(args []string) (*Config, *Command, error) {
+	cfg := Default()
+	settings := settingsOf(cfg)
+
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	printConfig := fs.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+
+	flagged := map[string]string{}
+	for _, s := range settings {
+		fs.Var(&flagValue{key: s.key, set: flagged, isBool: s.value.Kind() == reflect.Bool}, FlagName(s.key), "sets "+s.key+" (env "+EnvName(s.key)+")")
+	}
+	for alias, key := range flagAliases {
+		fs.Var(&flagValue{key: key, set: flagged, isBool: true}, alias, "alias of -"+FlagName(key))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(cfg, *configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, s := range settings {
+		raw, ok := lookupEnv(s.key)
+		if !ok {
+			continue
+		}
+		if err := setValue(s.value, raw); err != nil {
+			return nil, nil, fmt.Errorf("env %s: %w", EnvName(s.key), err)
+		}
+	}
+
+	for _, s := range settings {
+		raw, ok := flagged[s.key]
+		if !ok {
+			continue
+		}
+		if err := setValue(s.value, raw); err != nil {
+			return nil, nil, fmt.Errorf("flag -%s: %w", FlagName(s.key), err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return cfg, &Command{PrintConfig: *printConfig, Args: fs.Args()}, nil
+}
+
+// lookupEnv reads the variable for key, falling back to its legacy alias
+func lookupEnv(key string) (string, bool) {
+	if raw, ok := os.LookupEnv(EnvName(key)); ok {
+		return raw, true
+	}
+	for alias, aliased := range envAliases {
+		if aliased == key {
+			return os.LookupEnv(alias)
+		}
+	}
+	return "", false
+}
+
+// loadFile decodes a YAML or TOML file over cfg, rejecting unknown keys
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config file %s: unknown key %s", path, undecoded[0])
+		}
+	default:
+		return fmt.Errorf("config file %s: unsupported extension %q (want .yaml, .yml or .toml)", path, ext)
+	}
+	return nil
+}
+
+// settingsOf lists every leaf setting of cfg with its dotted key
+func settingsOf(cfg *Config) []setting {
+	var settings []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := prefix + field.Tag.Get("yaml")
+			if field.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), key+".")
+				continue
+			}
+			settings = append(settings, setting{key: key, value: v.Field(i), field: field})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return settings
+}
+
+// setValue parses raw into v; lists are comma separated and maps are
+// comma-separated key=value pairs
+func setValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, pair := range strings.Split(raw, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("%q is not key=value", pair)
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, value); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)), elem)
+		}
+		v.Set(m)
+	default:
+		panic("config: unsupported setting kind " + v.Kind().String())
+	}
+	return nil
+}
+
+// flagValue records a flag's raw value so it can be applied after the file and env
+type flagValue struct {
+	key    string
+	set    map[string]string
+	isBool bool
+}
+
+func (f *flagValue) String() string { return "" }
+
+func (f *flagValue) Set(raw string) error {
+	f.set[f.key] = raw
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool { return f.isBool }
+
+// Redacted returns a copy of c that is safe to print or log
+func (c *Config) Redacted() *Config {
+	copied := *c
+	copied.CORS.AllowedOrigins = append([]string(nil), c.CORS.AllowedOrigins...)
+	copied.RateLimit.TrustedProxies = append([]string(nil), c.RateLimit.TrustedProxies...)
+
+	for _, s := range settingsOf(&copied) {
+		switch s.field.Tag.Get("secret") {
+		case "true":
+			if s.value.String() != "" {
+				s.value.SetString("REDACTED")
+			}
+		case "userinfo":
+			if u, err := url.Parse(s.value.String()); err == nil && u.User != nil {
+				if _, hasPassword := u.User.Password(); hasPassword {
+					u.User = url.UserPassword(u.User.Username(), "REDACTED")
+					s.value.SetString(u.String())
+				}
+			}
+		}
+	}
+	return &copied
+}
+
+// Print writes the redacted configuration to w as YAML
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -3,18 +3,27 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
-	"time"
+	"log/slog"
 
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/metrics"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-func ConnectMongoDB(uri string) (*mongo.Client, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func ConnectMongoDB(cfg config.Mongo) (*mongo.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
 	defer cancel()
 
-	clientOptions := options.Client().ApplyURI(uri)
+	clientOptions := options.Client().
+		ApplyURI(cfg.URI).
+		SetMinPoolSize(cfg.MinPoolSize).
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetConnectTimeout(cfg.ConnectTimeout).
+		SetMonitor(commandMonitor()).
+		SetPoolMonitor(metrics.PoolMonitor())
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
@@ -26,10 +35,39 @@ func ConnectMongoDB(uri string) (*mongo.Client, error) {
 		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
 	}
 
-	log.Println("Successfully connected to MongoDB (localhost:27017)!")
+	slog.Info("connected to MongoDB", "database", cfg.Database)
 	return client, nil
 }
 
 func GetCollection(client *mongo.Client, dbName, collectionName string) *mongo.Collection {
 	return client.Database(dbName).Collection(collectionName)
 }
+
+// Ping returns a readiness check that pings the primary
+func Ping(client *mongo.Client) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	}
+}
+
+// Topology is what the server reports about the deployment it belongs to
+type Topology struct {
+	Me                string   `bson:"me" json:"me,omitempty"`
+	SetName           string   `bson:"setName" json:"set_name,omitempty"`
+	Primary           string   `bson:"primary" json:"primary,omitempty"`
+	Hosts             []string `bson:"hosts" json:"hosts,omitempty"`
+	IsWritablePrimary bool     `bson:"isWritablePrimary" json:"is_writable_primary"`
+	MaxWireVersion    int      `bson:"maxWireVersion" json:"max_wire_version"`
+	SessionsInUse     int      `bson:"-" json:"sessions_in_use"`
+}
+
+// DescribeTopology runs the hello command to describe the deployment
+func DescribeTopology(ctx context.Context, client *mongo.Client) (*Topology, error) {
+	var topology Topology
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&topology)
+	if err != nil {
+		return nil, err
+	}
+	topology.SessionsInUse = client.NumberSessionsInProgress()
+	return &topology, nil
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/teamserik/online-car-store/internal/metrics"
+	"github.com/teamserik/online-car-store/internal/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandMonitor fans driver command events out to metrics and logging
+func commandMonitor() *event.CommandMonitor {
+	monitors := []*event.CommandMonitor{
+		metrics.CommandMonitor(),
+		logMonitor(),
+		traceMonitor(),
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
+
+// logMonitor logs commands with the context of the operation that issued them,
+// so they carry the request ID of the HTTP request being served
+func logMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			slog.DebugContext(ctx, "mongo command",
+				"command", e.CommandName, "database", e.DatabaseName, "duration", e.Duration)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			slog.WarnContext(ctx, "mongo command failed",
+				"command", e.CommandName, "database", e.DatabaseName, "duration", e.Duration, "error", e.Failure)
+		},
+	}
+}
+
+// traceMonitor opens a client span per command as a child of the span in the
+// operation's context, so Mongo calls show up under the HTTP request
+func traceMonitor() *event.CommandMonitor {
+	var spans sync.Map // request ID -> trace.Span
+
+	end := func(requestID int64, failure string) {
+		value, ok := spans.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if failure != "" {
+			span.SetStatus(codes.Error, failure)
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			collection := commandCollection(e.Command, e.CommandName)
+			name := "mongo." + e.CommandName
+			if collection != "" {
+				name += " " + collection
+			}
+
+			_, span := tracing.Tracer().Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBNamespace(e.DatabaseName),
+					semconv.DBOperationName(e.CommandName),
+					semconv.DBCollectionName(collection),
+					attribute.String("server.address", e.ConnectionID),
+				),
+			)
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			end(e.RequestID, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			end(e.RequestID, e.Failure)
+		},
+	}
+}
+
+// commandCollection returns the collection a command targets, which most
+// commands carry as the value of their first element
+func commandCollection(command bson.Raw, name string) string {
+	value, err := command.LookupErr(name)
+	if err != nil {
+		return ""
+	}
+	collection, _ := value.StringValueOK()
+	return collection
+}
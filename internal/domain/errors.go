@@ -0,0 +1,104 @@
+// Package domain defines the errors shared by repositories and handlers, so
+// handlers never need to know about driver errors such as mongo.ErrNoDocuments.
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNotFound means the requested resource does not exist (or is not visible to the caller)
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the write clashes with existing data, e.g. a duplicate email
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidID means an identifier is malformed
+	ErrInvalidID = errors.New("invalid id")
+	// ErrForbidden means the caller may not perform the operation
+	ErrForbidden = errors.New("forbidden")
+	// ErrValidation means the input failed validation; see ValidationError
+	ErrValidation = errors.New("validation failed")
+	// ErrTooLarge means the request body exceeds the allowed size
+	ErrTooLarge = errors.New("too large")
+	// ErrPreconditionFailed means the resource changed since the version the caller based its write on
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is a domain error with a client-safe message. It matches its kind with errors.Is.
+type Error struct {
+	kind    error
+	message string
+}
+
+func (e *Error) Error() string { return e.message }
+
+func (e *Error) Unwrap() error { return e.kind }
+
+// NotFound returns an ErrNotFound error for the named resource ("car not found")
+func NotFound(resource string) error {
+	return &Error{kind: ErrNotFound, message: resource + " not found"}
+}
+
+// Conflict returns an ErrConflict error with the given message
+func Conflict(message string) error {
+	return &Error{kind: ErrConflict, message: message}
+}
+
+// InvalidID returns an ErrInvalidID error for the named resource ("invalid car ID")
+func InvalidID(resource string) error {
+	return &Error{kind: ErrInvalidID, message: "invalid " + resource + " ID"}
+}
+
+// Forbidden returns an ErrForbidden error with the given message
+func Forbidden(message string) error {
+	return &Error{kind: ErrForbidden, message: message}
+}
+
+// TooLarge returns an ErrTooLarge error with the given message
+func TooLarge(message string) error {
+	return &Error{kind: ErrTooLarge, message: message}
+}
+
+// PreconditionFailed returns an ErrPreconditionFailed error for the named resource
+func PreconditionFailed(resource string) error {
+	return &Error{kind: ErrPreconditionFailed, message: resource + " was modified by someone else; reload and try again"}
+}
+
+// FieldError describes why a single input field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every invalid field of an input
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// NewValidationError returns a ValidationError for a single field
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// Add records an invalid field
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns e if any field is invalid and nil otherwise
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error { return ErrValidation }
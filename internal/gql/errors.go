@@ -0,0 +1,73 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/problem"
+)
+
+// codes are the extensions.code values for the statuses the REST API would answer with
+var codes = map[int]string{
+	http.StatusBadRequest:            "BAD_USER_INPUT",
+	http.StatusUnauthorized:          "UNAUTHENTICATED",
+	http.StatusForbidden:             "FORBIDDEN",
+	http.StatusNotFound:              "NOT_FOUND",
+	http.StatusConflict:              "CONFLICT",
+	http.StatusPreconditionFailed:    "PRECONDITION_FAILED",
+	http.StatusRequestEntityTooLarge: "TOO_LARGE",
+	http.StatusGatewayTimeout:        "TIMEOUT",
+}
+
+// errUnauthenticated is returned by fields that need a signed-in user
+var errUnauthenticated = &Error{status: http.StatusUnauthorized, message: "Unauthorized"}
+
+// Error is a resolver error with a client-safe message. graphql-go reports
+// its Extensions, so clients can tell errors apart by code like the REST
+// API's statuses.
+type Error struct {
+	status  int
+	message string
+	fields  []domain.FieldError
+}
+
+func (e *Error) Error() string { return e.message }
+
+func (e *Error) Extensions() map[string]any {
+	ext := map[string]any{"code": code(e.status), "status": e.status}
+	if len(e.fields) > 0 {
+		ext["errors"] = e.fields
+	}
+	return ext
+}
+
+// resolverError maps err like problem.Error does: domain errors keep their
+// message, anything else is logged and hidden
+func resolverError(ctx context.Context, err error) error {
+	status := problem.StatusFor(err)
+	e := &Error{status: status, message: err.Error()}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		e.message = "One or more fields are invalid"
+		for _, f := range validationErr.Fields {
+			e.fields = append(e.fields, domain.FieldError{Field: camelCase(f.Field), Message: f.Message})
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx, "graphql resolver failed", "error", err)
+		e.message = "An unexpected error occurred"
+	}
+	return e
+}
+
+func code(status int) string {
+	if c, ok := codes[status]; ok {
+		return c
+	}
+	return "INTERNAL_SERVER_ERROR"
+}
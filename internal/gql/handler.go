@@ -0,0 +1,139 @@
+// Package gql serves the GraphQL API at /graphql: cars with their reviews,
+// dealer and favorite state, users, favorites and reviews, and mutations
+// mirroring the REST handlers. Fields that point at other records load them
+// in batches, one repository call per level of the query, and queries are
+// checked against depth and complexity limits before they run.
+// Registration and login stay on the REST API, behind its rate limits.
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	gqlsource "github.com/graphql-go/graphql/language/source"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/problem"
+	"github.com/teamserik/online-car-store/internal/validate"
+)
+
+// Request is a GraphQL request, sent as a JSON body or, for queries, as URL parameters
+type Request struct {
+	Query         string         `json:"query" validate:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    map[string]any `json:"extensions"`
+}
+
+// Handler serves GraphQL over HTTP. Mutations must be POSTed; GET only runs
+// queries. Authentication comes from middleware.OptionalAuthenticate.
+func Handler(d Deps, cfg config.GraphQL) http.HandlerFunc {
+	schema, err := NewSchema(d)
+	if err != nil {
+		// The schema is fixed at compile time, so this is a bug
+		panic(fmt.Sprintf("gql: build schema: %v", err))
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if r.Method == http.MethodGet {
+			if err := readQuery(r, &req); err != nil {
+				problem.Error(w, r, err)
+				return
+			}
+		} else if err := validate.Decode(w, r, &req); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		doc, err := parser.Parse(parser.ParseParams{Source: gqlsource.NewSource(&gqlsource.Source{
+			Body: []byte(req.Query),
+			Name: "GraphQL request",
+		})})
+		if err != nil {
+			writeResult(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+
+		if result := graphql.ValidateDocument(&schema, doc, nil); !result.IsValid {
+			writeResult(w, http.StatusBadRequest, &graphql.Result{Errors: result.Errors})
+			return
+		}
+
+		depth, complexity, err := measure(schema, doc, req.OperationName, req.Variables, cfg.MaxComplexity)
+		if err != nil {
+			writeResult(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		if depth > cfg.MaxDepth {
+			writeLimitError(w, "QUERY_TOO_DEEP", fmt.Sprintf("query depth %d exceeds the limit of %d", depth, cfg.MaxDepth))
+			return
+		}
+		if complexity > cfg.MaxComplexity {
+			writeLimitError(w, "QUERY_TOO_COMPLEX", fmt.Sprintf("query complexity exceeds the limit of %d", cfg.MaxComplexity))
+			return
+		}
+
+		if r.Method == http.MethodGet && isMutation(doc, req.OperationName) {
+			w.Header().Set("Allow", http.MethodPost)
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Mutations must be sent with POST")
+			return
+		}
+
+		userID, _ := viewer(r.Context())
+		ctx := context.WithValue(r.Context(), loadersKey{}, newLoaders(d, userID))
+
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           doc,
+			OperationName: req.OperationName,
+			Args:          req.Variables,
+			Context:       ctx,
+		})
+		writeResult(w, http.StatusOK, result)
+	}
+}
+
+// readQuery reads a GET request's query, operationName and variables parameters
+func readQuery(r *http.Request, req *Request) error {
+	q := r.URL.Query()
+	req.Query = q.Get("query")
+	req.OperationName = q.Get("operationName")
+	if req.Query == "" {
+		return domain.NewValidationError("query", "is required")
+	}
+	if vars := q.Get("variables"); vars != "" {
+		if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+			return domain.NewValidationError("variables", "must be a JSON object")
+		}
+	}
+	return nil
+}
+
+func isMutation(doc *ast.Document, operationName string) bool {
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok && (operationName == "" || (op.Name != nil && op.Name.Value == operationName)) {
+			return op.Operation == ast.OperationTypeMutation
+		}
+	}
+	return false
+}
+
+func writeLimitError(w http.ResponseWriter, code, message string) {
+	writeResult(w, http.StatusBadRequest, &graphql.Result{Errors: []gqlerrors.FormattedError{{
+		Message:    message,
+		Extensions: map[string]any{"code": code},
+	}}})
+}
+
+func writeResult(w http.ResponseWriter, status int, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,264 @@
+package gql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/gql"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// graphQLResult is a decoded GraphQL response
+type graphQLResult struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// TestHandler checks the GraphQL handler: nested fields are loaded in
+// batches, oversized queries are rejected, and mutations need a user
+func TestHandler(t *testing.T) {
+	repos := repositorytest.NewMemoryRepos()
+	cars := &countingCars{CarRepository: repos.Cars}
+	users := &countingUsers{UserRepository: repos.Users}
+	reviews := &countingReviews{ReviewRepository: repos.Reviews}
+	deps := gql.Deps{Cars: cars, Users: users, Favorites: repos.Favorites, Reviews: reviews}
+	cfg := config.Default()
+	tokens := auth.NewManager(cfg.Auth)
+	handler := middleware.OptionalAuthenticate(tokens)(gql.Handler(deps, cfg.GraphQL))
+
+	ctx := context.Background()
+	var dealers []*model.User
+	for i := range 3 {
+		u := &model.User{Username: fmt.Sprintf("dealer%d", i), Email: fmt.Sprintf("dealer%d@example.com", i), Role: "user"}
+		if err := deps.Users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		dealers = append(dealers, u)
+	}
+	for i := range 6 {
+		car := &model.Car{Make: "Toyota", Model: fmt.Sprintf("Model %d", i), Year: 2020, DealerID: dealers[i%len(dealers)].ID}
+		if err := deps.Cars.Create(ctx, car); err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range dealers[:2] {
+			review := &model.Review{CarID: car.ID, UserID: u.ID, Username: u.Username, Rating: 4, Comment: "Fine"}
+			if err := deps.Reviews.CreateReview(ctx, review); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	token, err := tokens.GenerateToken(dealers[0].ID, dealers[0].Email, dealers[0].Username, dealers[0].Role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(t *testing.T, token, query string) (int, graphQLResult) {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{"query": query})
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var result graphQLResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("decode %s: %v", rec.Body.String(), err)
+		}
+		return rec.Code, result
+	}
+
+	t.Run("Batching", func(t *testing.T) {
+		cars.reset()
+		users.reset()
+		reviews.reset()
+
+		status, result := post(t, token, `{ cars(first: 10) { model isFavorite dealer { username } reviews { rating user { username } car { model } } } }`)
+		if status != http.StatusOK || len(result.Errors) > 0 {
+			t.Fatalf("status %d errors %v", status, result.Errors)
+		}
+		var data []struct {
+			Dealer  *struct{ Username string } `json:"dealer"`
+			Reviews []struct {
+				User struct{ Username string } `json:"user"`
+			} `json:"reviews"`
+		}
+		if err := json.Unmarshal(result.Data["cars"], &data); err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 6 || data[0].Dealer == nil || len(data[0].Reviews) != 2 || data[0].Reviews[0].User.Username == "" {
+			t.Fatalf("unexpected data %s", result.Data["cars"])
+		}
+
+		// At most one call per level: the dealers, then the reviewers and the
+		// reviewed cars; graphql-go may run both levels' users in one
+		if n := users.single.Load(); n != 0 {
+			t.Errorf("%d FindByID calls, want batched loads", n)
+		}
+		if n := users.batch.Load(); n < 1 || n > 2 {
+			t.Errorf("%d FindByIDs calls, want 1 or 2", n)
+		}
+		if n := reviews.batch.Load(); n != 1 {
+			t.Errorf("%d GetReviewsByCarIDs calls, want 1", n)
+		}
+		if n := cars.single.Load(); n != 0 {
+			t.Errorf("%d GetByID calls, want batched loads", n)
+		}
+		if n := cars.batch.Load(); n != 1 {
+			t.Errorf("%d GetByIDs calls, want 1", n)
+		}
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		cases := []struct{ code, query string }{
+			{"QUERY_TOO_DEEP", `{ cars { reviews { car { reviews { car { reviews { car { reviews { rating } } } } } } } } }`},
+			{"QUERY_TOO_COMPLEX", `{ cars(first: 100) { reviews(first: 100) { user { username } } } }`},
+			// Page sizes past what the resolvers return must neither wrap the count nor lower it
+			{"QUERY_TOO_COMPLEX", `{ cars(first: 1073741824) { reviews(first: 1990191023) { car { reviews(first: 121) { car { reviews(first: 101) { id } } } } } } }`},
+		}
+		for _, c := range cases {
+			status, result := post(t, "", c.query)
+			if status != http.StatusBadRequest || len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != c.code {
+				t.Errorf("%s: status %d errors %v, want 400 %s", c.query, status, result.Errors, c.code)
+			}
+		}
+
+		if status, result := post(t, "", `{ cars(first: 1000000) { id } }`); status != http.StatusOK || len(result.Errors) > 0 || cars.limit.Load() != 100 {
+			t.Errorf("cars(first: 1000000): status %d errors %v limit %d, want a page of 100 from the repository", status, result.Errors, cars.limit.Load())
+		}
+		if status, result := post(t, "", `{ cars(first: 0) { id } }`); status != http.StatusOK || len(result.Errors) > 0 || cars.limit.Load() != 20 {
+			t.Errorf("cars(first: 0): status %d errors %v limit %d, want the default page of 20", status, result.Errors, cars.limit.Load())
+		}
+	})
+
+	t.Run("Auth", func(t *testing.T) {
+		if _, result := post(t, "", `{ me { username } cars { isFavorite } }`); len(result.Errors) > 0 || string(result.Data["me"]) != "null" {
+			t.Errorf("signed out: data %v errors %v, want me null", result.Data, result.Errors)
+		}
+		status, result := post(t, "", `{ favorites { id } }`)
+		if status != http.StatusOK || len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != "UNAUTHENTICATED" {
+			t.Errorf("favorites without a token: status %d errors %v, want UNAUTHENTICATED", status, result.Errors)
+		}
+
+		mutation := `mutation { createCar(input: {make: "Lada", model: "Niva", year: 2020, price: 1, bodyType: "SUV", fuelType: "Gasoline", transmission: "Manual", color: "White"}) { id } }`
+		if _, result := post(t, "", mutation); len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != "UNAUTHENTICATED" {
+			t.Errorf("createCar without a token: errors %v, want UNAUTHENTICATED", result.Errors)
+		}
+		if _, result := post(t, token, mutation); len(result.Errors) > 0 {
+			t.Errorf("createCar: errors %v", result.Errors)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(mutation), nil))
+		if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+			t.Errorf("mutation over GET: status %d Allow %q, want 405 Allow POST", rec.Code, rec.Header().Get("Allow"))
+		}
+	})
+
+	t.Run("Ownership", func(t *testing.T) {
+		car := &model.Car{Make: "Lada", Model: "Vesta", Year: 2021, DealerID: dealers[1].ID}
+		if err := repos.Cars.Create(ctx, car); err != nil {
+			t.Fatal(err)
+		}
+		admin, err := tokens.GenerateToken(primitive.NewObjectID(), "admin@example.com", "admin", "admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mutations := []string{
+			fmt.Sprintf(`mutation { updateCar(id: %q, input: {make: "Lada", model: "Vesta", year: 2021, price: 2, bodyType: "Sedan", fuelType: "Gasoline", transmission: "Manual", color: "Red"}) { id } }`, car.ID.Hex()),
+			fmt.Sprintf(`mutation { patchCar(id: %q, input: {price: 3}) { id } }`, car.ID.Hex()),
+			fmt.Sprintf(`mutation { deleteCar(id: %q) }`, car.ID.Hex()),
+		}
+		for _, mutation := range mutations {
+			if _, result := post(t, token, mutation); len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != "FORBIDDEN" {
+				t.Errorf("%s on another dealer's car: errors %v, want FORBIDDEN", mutation, result.Errors)
+			}
+		}
+		for _, mutation := range mutations {
+			if _, result := post(t, admin, mutation); len(result.Errors) > 0 {
+				t.Errorf("%s as admin: errors %v", mutation, result.Errors)
+			}
+		}
+	})
+
+	t.Run("ValidationErrors", func(t *testing.T) {
+		_, result := post(t, token, `mutation { createCar(input: {make: "Lada", model: "Niva", year: 1800, price: 1, bodyType: "Tank", fuelType: "Gasoline", transmission: "Manual", color: "White"}) { id } }`)
+		if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != "BAD_USER_INPUT" {
+			t.Fatalf("errors %v, want BAD_USER_INPUT", result.Errors)
+		}
+		fields, _ := result.Errors[0].Extensions["errors"].([]any)
+		if len(fields) != 2 {
+			t.Errorf("field errors %v, want year and bodyType", fields)
+		}
+	})
+}
+
+type countingCars struct {
+	repository.CarRepository
+	single, batch atomic.Int64
+	limit         atomic.Int64 // of the last List call
+}
+
+func (c *countingCars) List(ctx context.Context, filter *model.FilterParams) ([]*model.Car, error) {
+	c.limit.Store(int64(filter.Limit))
+	return c.CarRepository.List(ctx, filter)
+}
+
+func (c *countingCars) reset() { c.single.Store(0); c.batch.Store(0) }
+
+func (c *countingCars) GetByID(ctx context.Context, id string) (*model.Car, error) {
+	c.single.Add(1)
+	return c.CarRepository.GetByID(ctx, id)
+}
+
+func (c *countingCars) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.Car, error) {
+	c.batch.Add(1)
+	return c.CarRepository.GetByIDs(ctx, ids)
+}
+
+type countingUsers struct {
+	repository.UserRepository
+	single, batch atomic.Int64
+}
+
+func (u *countingUsers) reset() { u.single.Store(0); u.batch.Store(0) }
+
+func (u *countingUsers) FindByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
+	u.single.Add(1)
+	return u.UserRepository.FindByID(ctx, id)
+}
+
+func (u *countingUsers) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.User, error) {
+	u.batch.Add(1)
+	return u.UserRepository.FindByIDs(ctx, ids)
+}
+
+type countingReviews struct {
+	repository.ReviewRepository
+	batch atomic.Int64
+}
+
+func (r *countingReviews) reset() { r.batch.Store(0) }
+
+func (r *countingReviews) GetReviewsByCarIDs(ctx context.Context, carIDs []primitive.ObjectID, params model.ReviewListParams) (map[primitive.ObjectID][]model.Review, error) {
+	r.batch.Add(1)
+	return r.ReviewRepository.GetReviewsByCarIDs(ctx, carIDs, params)
+}
@@ -0,0 +1,106 @@
+package gql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/teamserik/online-car-store/internal/validate"
+)
+
+// inputObject describes a model input struct as a GraphQL input type, so
+// mutations take the same fields as the REST bodies. Fields are named after
+// their JSON keys in camelCase; "required" fields are non-null.
+func inputObject(name, description string, model any) *graphql.InputObject {
+	fields := graphql.InputObjectConfigFieldMap{}
+	t := reflect.TypeOf(model)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := jsonKey(f)
+		if key == "" {
+			continue
+		}
+
+		var typ graphql.Input = scalarFor(f.Type, key)
+		if rules := strings.Split(f.Tag.Get("validate"), ","); rules[0] == "required" && f.Type.Kind() != reflect.Pointer {
+			typ = graphql.NewNonNull(typ)
+		}
+		fields[camelCase(key)] = &graphql.InputObjectFieldConfig{Type: typ}
+	}
+
+	return graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        name,
+		Description: description,
+		Fields:      fields,
+	})
+}
+
+// decodeInput copies a GraphQL input object argument into dst, a pointer to
+// the struct it was described from, and validates the result
+func decodeInput(arg any, dst any) error {
+	values, _ := arg.(map[string]any)
+	v := reflect.ValueOf(dst).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		value, ok := values[camelCase(jsonKey(f))]
+		if !ok || value == nil {
+			continue
+		}
+
+		field := v.Field(i)
+		target := field.Type()
+		if target.Kind() == reflect.Pointer {
+			target = target.Elem()
+		}
+		rv := reflect.ValueOf(value)
+		if !rv.Type().ConvertibleTo(target) {
+			return fmt.Errorf("gql: cannot decode %T into %s", value, f.Name)
+		}
+		rv = rv.Convert(target)
+		if field.Kind() == reflect.Pointer {
+			ptr := reflect.New(target)
+			ptr.Elem().Set(rv)
+			rv = ptr
+		}
+		field.Set(rv)
+	}
+	return validate.Struct(dst)
+}
+
+func scalarFor(t reflect.Type, key string) *graphql.Scalar {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+		return graphql.Int
+	case reflect.Float64:
+		return graphql.Float
+	case reflect.Bool:
+		return graphql.Boolean
+	}
+	if key == "id" || strings.HasSuffix(key, "_id") {
+		return graphql.ID
+	}
+	return graphql.String
+}
+
+func jsonKey(f reflect.StructField) string {
+	key, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+// camelCase turns a JSON key like body_type into bodyType
+func camelCase(key string) string {
+	parts := strings.Split(key, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
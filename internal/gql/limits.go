@@ -0,0 +1,177 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// pageArgs are the arguments that set how many items a list field returns
+var pageArgs = []string{"first", "limit"}
+
+// maxPageSizes are the page sizes the list fields' resolvers clamp to, by type and field
+var maxPageSizes = map[string]int{
+	"Query.cars":      maxCars,
+	"Query.favorites": maxFavorites,
+	"Car.reviews":     maxReviews,
+}
+
+// cost measures an operation before it runs: depth is the deepest field
+// nesting, complexity counts every field with list items multiplied by the
+// page size. Introspection fields are free, the schema being small and static.
+// Complexity stops counting just past limit, so huge page sizes cannot wrap it.
+type cost struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+	limit     int
+}
+
+// measure returns the depth and complexity of the operation that will run,
+// or an error when there is no such operation. A complexity over maxComplexity
+// is reported as maxComplexity+1.
+func measure(schema graphql.Schema, doc *ast.Document, operationName string, variables map[string]any, maxComplexity int) (depth, complexity int, err error) {
+	c := cost{schema: schema, fragments: map[string]*ast.FragmentDefinition{}, variables: variables, limit: maxComplexity}
+
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operations = append(operations, def)
+			}
+		}
+	}
+	if len(operations) != 1 {
+		return 0, 0, fmt.Errorf("the request must name one operation to run")
+	}
+
+	op := operations[0]
+	root := schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	depth, complexity = c.selectionSet(root, op.SelectionSet)
+	return depth, complexity, nil
+}
+
+func (c cost) selectionSet(parent *graphql.Object, set *ast.SelectionSet) (depth, complexity int) {
+	if parent == nil || set == nil {
+		return 0, 0
+	}
+
+	for _, sel := range set.Selections {
+		var d, n int
+		switch sel := sel.(type) {
+		case *ast.Field:
+			d, n = c.field(parent, sel)
+		case *ast.InlineFragment:
+			d, n = c.selectionSet(c.typeCondition(parent, sel.TypeCondition), sel.SelectionSet)
+		case *ast.FragmentSpread:
+			if frag, ok := c.fragments[sel.Name.Value]; ok {
+				d, n = c.selectionSet(c.typeCondition(parent, frag.TypeCondition), frag.SelectionSet)
+			}
+		}
+		depth = max(depth, d)
+		complexity = c.saturate(complexity + n)
+	}
+	return depth, complexity
+}
+
+func (c cost) field(parent *graphql.Object, field *ast.Field) (depth, complexity int) {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return 0, 0
+	}
+
+	def, ok := parent.Fields()[field.Name.Value]
+	if !ok {
+		return 1, 1
+	}
+
+	child, _ := unwrap(def.Type).(*graphql.Object)
+	d, n := c.selectionSet(child, field.SelectionSet)
+	return d + 1, c.saturate(1 + c.pageSize(parent, def, field)*n)
+}
+
+// saturate caps n just past the limit; page sizes are clamped, so the
+// products it is applied to stay far from overflowing
+func (c cost) saturate(n int) int {
+	return min(n, c.limit+1)
+}
+
+// pageSize is the page argument given in the query, else its default, else 1,
+// clamped to what the field's resolver returns at most
+func (c cost) pageSize(parent *graphql.Object, def *graphql.FieldDefinition, field *ast.Field) int {
+	n := c.requestedPageSize(def, field)
+	if limit, ok := maxPageSizes[parent.Name()+"."+def.Name]; ok {
+		n = min(n, limit)
+	}
+	return n
+}
+
+// requestedPageSize is the page argument given in the query when positive,
+// else its default, else 1; resolvers use the default for sizes below 1
+func (c cost) requestedPageSize(def *graphql.FieldDefinition, field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		for _, name := range pageArgs {
+			if arg.Name.Value == name {
+				if n, ok := c.intValue(arg.Value); ok && n >= 1 {
+					return n
+				}
+			}
+		}
+	}
+	for _, arg := range def.Args {
+		for _, name := range pageArgs {
+			if arg.Name() == name {
+				if n, ok := arg.DefaultValue.(int); ok {
+					return max(n, 1)
+				}
+			}
+		}
+	}
+	return 1
+}
+
+func (c cost) intValue(v ast.Value) (int, bool) {
+	switch v := v.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(v.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := c.variables[v.Name.Value].(type) {
+		case float64:
+			return int(n), true
+		case int:
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func (c cost) typeCondition(parent *graphql.Object, cond *ast.Named) *graphql.Object {
+	if cond == nil {
+		return parent
+	}
+	obj, _ := c.schema.Type(cond.Name.Value).(*graphql.Object)
+	return obj
+}
+
+// unwrap strips the list and non-null wrappers off t
+func unwrap(t graphql.Type) graphql.Type {
+	for {
+		switch w := t.(type) {
+		case *graphql.NonNull:
+			t = w.OfType
+		case *graphql.List:
+			t = w.OfType
+		default:
+			return t
+		}
+	}
+}
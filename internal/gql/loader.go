@@ -0,0 +1,139 @@
+package gql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// loader batches the keys asked for by sibling resolvers into one fetch.
+// load only queues the key and returns a thunk; graphql-go runs thunks after
+// the whole level has resolved, so the first thunk fetches every queued key.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending map[K]bool
+	loaded  map[K]V
+	failed  map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		pending: map[K]bool{},
+		loaded:  map[K]V{},
+		failed:  map[K]error{},
+	}
+}
+
+// load queues key and returns a thunk resolving to its value, or the zero value when it does not exist
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (any, error) {
+	l.mu.Lock()
+	if !l.done(key) {
+		l.pending[key] = true
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if !l.done(key) {
+			l.flush(ctx)
+		}
+		if err := l.failed[key]; err != nil {
+			return nil, err
+		}
+		return l.loaded[key], nil
+	}
+}
+
+func (l *loader[K, V]) done(key K) bool {
+	_, loaded := l.loaded[key]
+	_, failed := l.failed[key]
+	return loaded || failed
+}
+
+// flush fetches every pending key; the caller holds mu
+func (l *loader[K, V]) flush(ctx context.Context) {
+	keys := make([]K, 0, len(l.pending))
+	for key := range l.pending {
+		keys = append(keys, key)
+	}
+	clear(l.pending)
+
+	values, err := l.fetch(ctx, keys)
+	if err != nil {
+		err = resolverError(ctx, err)
+	}
+	for _, key := range keys {
+		if err != nil {
+			l.failed[key] = err
+			continue
+		}
+		l.loaded[key] = values[key]
+	}
+}
+
+// loaders are the per-request batches over the repositories
+type loaders struct {
+	cars      *loader[primitive.ObjectID, *model.Car]
+	users     *loader[primitive.ObjectID, *model.User]
+	favorites *loader[primitive.ObjectID, bool]
+
+	mu      sync.Mutex
+	reviews map[model.ReviewListParams]*loader[primitive.ObjectID, []model.Review]
+	deps    Deps
+	userID  primitive.ObjectID
+}
+
+func newLoaders(d Deps, userID primitive.ObjectID) *loaders {
+	return &loaders{
+		cars: newLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*model.Car, error) {
+			cars, err := d.Cars.GetByIDs(ctx, ids)
+			return byID(cars, func(c *model.Car) primitive.ObjectID { return c.ID }), err
+		}),
+		users: newLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*model.User, error) {
+			users, err := d.Users.FindByIDs(ctx, ids)
+			return byID(users, func(u *model.User) primitive.ObjectID { return u.ID }), err
+		}),
+		favorites: newLoader(func(ctx context.Context, carIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+			ids, err := d.Favorites.FavoriteCarIDs(ctx, userID, carIDs)
+			favorited := make(map[primitive.ObjectID]bool, len(ids))
+			for _, id := range ids {
+				favorited[id] = true
+			}
+			return favorited, err
+		}),
+		reviews: map[model.ReviewListParams]*loader[primitive.ObjectID, []model.Review]{},
+		deps:    d,
+		userID:  userID,
+	}
+}
+
+// reviewsFor returns the loader for one page of reviews; cars asking for the
+// same page and sort share a batch
+func (l *loaders) reviewsFor(params model.ReviewListParams) *loader[primitive.ObjectID, []model.Review] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ld, ok := l.reviews[params]; ok {
+		return ld
+	}
+	ld := newLoader(func(ctx context.Context, carIDs []primitive.ObjectID) (map[primitive.ObjectID][]model.Review, error) {
+		return l.deps.Reviews.GetReviewsByCarIDs(ctx, carIDs, params)
+	})
+	l.reviews[params] = ld
+	return ld
+}
+
+func byID[V any](values []V, id func(V) primitive.ObjectID) map[primitive.ObjectID]V {
+	m := make(map[primitive.ObjectID]V, len(values))
+	for _, v := range values {
+		m[id(v)] = v
+	}
+	return m
+}
@@ -0,0 +1,658 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/metrics"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Deps are the repositories the schema resolves against
+type Deps struct {
+	Cars      repository.CarRepository
+	Users     repository.UserRepository
+	Favorites repository.FavoriteRepository
+	Reviews   repository.ReviewRepository
+}
+
+type loadersKey struct{}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// viewer is the signed-in user, set by middleware.OptionalAuthenticate
+func viewer(ctx context.Context) (primitive.ObjectID, bool) {
+	id, ok := ctx.Value(middleware.UserIDKey).(primitive.ObjectID)
+	return id, ok
+}
+
+func requireViewer(ctx context.Context) (primitive.ObjectID, error) {
+	id, ok := viewer(ctx)
+	if !ok {
+		return id, errUnauthenticated
+	}
+	return id, nil
+}
+
+// source returns the parent value of a field; lists hold values or pointers
+func source[T any](src any) *T {
+	switch v := src.(type) {
+	case *T:
+		return v
+	case T:
+		return &v
+	}
+	return nil
+}
+
+// prop resolves a field from its parent
+func prop[T any](typ graphql.Output, get func(*T) any) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return get(source[T](p.Source)), nil
+		},
+	}
+}
+
+// hex renders an optional reference, nil when unset
+func hex(id primitive.ObjectID) any {
+	if id.IsZero() {
+		return nil
+	}
+	return id.Hex()
+}
+
+// optional is s, or null when s is empty
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func objectID(arg any, resource string) (primitive.ObjectID, error) {
+	s, _ := arg.(string)
+	id, err := primitive.ObjectIDFromHex(s)
+	if err != nil {
+		return id, domain.InvalidID(resource)
+	}
+	return id, nil
+}
+
+// intArg and stringArg read optional arguments; an explicit null reads as zero,
+// which the repositories replace with their defaults
+func intArg(args map[string]any, name string) int {
+	v, _ := args[name].(int)
+	return v
+}
+
+func stringArg(args map[string]any, name string) string {
+	v, _ := args[name].(string)
+	return v
+}
+
+// version is the optional version argument of a write, AnyVersion when absent
+func version(args map[string]any) int64 {
+	if v, ok := args["version"].(int); ok {
+		return int64(v)
+	}
+	return repository.AnyVersion
+}
+
+// Most items a list field returns, whatever the query asks for; the query cost uses them too.
+// maxCars caps the cars query; the REST list is unpaged, but a query can nest a lot under each car.
+const (
+	maxCars      = 100
+	maxReviews   = 100
+	maxFavorites = 200
+)
+
+// defaultCars is the cars query's page size when first is missing or below 1
+const defaultCars = 20
+
+var (
+	carSort = graphql.NewEnum(graphql.EnumConfig{
+		Name: "CarSort",
+		Values: graphql.EnumValueConfigMap{
+			"NEWEST":     {Value: model.CarSortNewest},
+			"PRICE_ASC":  {Value: model.CarSortPriceAsc},
+			"PRICE_DESC": {Value: model.CarSortPriceDesc},
+			"RATING":     {Value: model.CarSortRating},
+		},
+	})
+	reviewSort = graphql.NewEnum(graphql.EnumConfig{
+		Name: "ReviewSort",
+		Values: graphql.EnumValueConfigMap{
+			"NEWEST":       {Value: model.ReviewSortNewest},
+			"OLDEST":       {Value: model.ReviewSortOldest},
+			"HIGHEST":      {Value: model.ReviewSortHighest},
+			"LOWEST":       {Value: model.ReviewSortLowest},
+			"MOST_HELPFUL": {Value: model.ReviewSortMostHelpful},
+		},
+	})
+	favoriteSort = graphql.NewEnum(graphql.EnumConfig{
+		Name: "FavoriteSort",
+		Values: graphql.EnumValueConfigMap{
+			"NEWEST":     {Value: model.FavoriteSortNewest},
+			"OLDEST":     {Value: model.FavoriteSortOldest},
+			"PRICE_ASC":  {Value: model.FavoriteSortPriceAsc},
+			"PRICE_DESC": {Value: model.FavoriteSortPriceDesc},
+		},
+	})
+
+	createCarInput = inputObject("CreateCarInput", "A car to list for sale", model.CreateCarInput{})
+	updateCarInput = inputObject("UpdateCarInput", "Every field of a car, replacing the stored ones", model.UpdateCarInput{})
+	patchCarInput  = inputObject("PatchCarInput", "The fields of a car to change; omitted fields are kept", model.PatchCarInput{})
+	favoriteInput  = inputObject("AddFavoriteInput", "A car to add to the viewer's favorites", model.AddFavoriteInput{})
+	reviewInput    = inputObject("ReviewInput", "The rating and comment of a review", model.UpdateReviewInput{})
+)
+
+// NewSchema builds the GraphQL schema over d
+func NewSchema(d Deps) (graphql.Schema, error) {
+	var carType, userType, reviewType *graphql.Object
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "A registered user; contact details are only visible to the user themselves",
+		Fields: graphql.Fields{
+			"id":        prop(graphql.NewNonNull(graphql.ID), func(u *model.User) any { return u.ID.Hex() }),
+			"username":  prop(graphql.NewNonNull(graphql.String), func(u *model.User) any { return u.Username }),
+			"firstName": prop(graphql.NewNonNull(graphql.String), func(u *model.User) any { return u.FirstName }),
+			"lastName":  prop(graphql.NewNonNull(graphql.String), func(u *model.User) any { return u.LastName }),
+			"role":      prop(graphql.NewNonNull(graphql.String), func(u *model.User) any { return u.Role }),
+			"createdAt": prop(graphql.NewNonNull(graphql.DateTime), func(u *model.User) any { return u.CreatedAt }),
+			"email":     private(graphql.String, func(u *model.User) any { return u.Email }),
+			"phone":     private(graphql.String, func(u *model.User) any { return u.Phone }),
+		},
+	})
+
+	replyType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ReviewReply",
+		Description: "The public reply of the listing's dealer or an admin",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return loadersFrom(p.Context).users.load(p.Context, source[model.ReviewReply](p.Source).UserID), nil
+				},
+			},
+			"username":  prop(graphql.NewNonNull(graphql.String), func(r *model.ReviewReply) any { return r.Username }),
+			"role":      prop(graphql.NewNonNull(graphql.String), func(r *model.ReviewReply) any { return r.Role }),
+			"comment":   prop(graphql.NewNonNull(graphql.String), func(r *model.ReviewReply) any { return r.Comment }),
+			"createdAt": prop(graphql.NewNonNull(graphql.DateTime), func(r *model.ReviewReply) any { return r.CreatedAt }),
+		},
+	})
+
+	reviewType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Review",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": prop(graphql.NewNonNull(graphql.ID), func(r *model.Review) any { return r.ID.Hex() }),
+				"car": &graphql.Field{
+					Type: carType,
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return loadersFrom(p.Context).cars.load(p.Context, source[model.Review](p.Source).CarID), nil
+					},
+				},
+				"user": &graphql.Field{
+					Type: userType,
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return loadersFrom(p.Context).users.load(p.Context, source[model.Review](p.Source).UserID), nil
+					},
+				},
+				"username":       prop(graphql.NewNonNull(graphql.String), func(r *model.Review) any { return r.Username }),
+				"rating":         prop(graphql.NewNonNull(graphql.Int), func(r *model.Review) any { return r.Rating }),
+				"comment":        prop(graphql.NewNonNull(graphql.String), func(r *model.Review) any { return r.Comment }),
+				"helpfulCount":   prop(graphql.NewNonNull(graphql.Int), func(r *model.Review) any { return r.HelpfulCount }),
+				"unhelpfulCount": prop(graphql.NewNonNull(graphql.Int), func(r *model.Review) any { return r.UnhelpfulCount }),
+				"reply":          prop(replyType, func(r *model.Review) any { return r.Reply }),
+				"version":        prop(graphql.NewNonNull(graphql.Int), func(r *model.Review) any { return r.Version }),
+				"createdAt":      prop(graphql.NewNonNull(graphql.DateTime), func(r *model.Review) any { return r.CreatedAt }),
+				"updatedAt":      prop(graphql.NewNonNull(graphql.DateTime), func(r *model.Review) any { return r.UpdatedAt }),
+			}
+		}),
+	})
+
+	carType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Car",
+		Fields: graphql.Fields{
+			"id":           prop(graphql.NewNonNull(graphql.ID), func(c *model.Car) any { return c.ID.Hex() }),
+			"make":         prop(graphql.NewNonNull(graphql.String), func(c *model.Car) any { return c.Make }),
+			"model":        prop(graphql.NewNonNull(graphql.String), func(c *model.Car) any { return c.Model }),
+			"year":         prop(graphql.NewNonNull(graphql.Int), func(c *model.Car) any { return c.Year }),
+			"price":        prop(graphql.NewNonNull(graphql.Float), func(c *model.Car) any { return c.Price }),
+			"mileage":      prop(graphql.NewNonNull(graphql.Int), func(c *model.Car) any { return c.Mileage }),
+			"bodyType":     prop(graphql.NewNonNull(graphql.String), func(c *model.Car) any { return c.BodyType }),
+			"fuelType":     prop(graphql.NewNonNull(graphql.String), func(c *model.Car) any { return c.FuelType }),
+			"transmission": prop(graphql.NewNonNull(graphql.String), func(c *model.Car) any { return c.Transmission }),
+			"color":        prop(graphql.NewNonNull(graphql.String), func(c *model.Car) any { return c.Color }),
+			"horsepower":   prop(graphql.NewNonNull(graphql.Int), func(c *model.Car) any { return c.HorsePower }),
+			"engineSize":   prop(graphql.NewNonNull(graphql.Float), func(c *model.Car) any { return c.EngineSize }),
+			"description":  prop(graphql.NewNonNull(graphql.String), func(c *model.Car) any { return c.Description }),
+			"imageUrl":     prop(graphql.NewNonNull(graphql.String), func(c *model.Car) any { return c.ImageURL }),
+			"dealerId":     prop(graphql.ID, func(c *model.Car) any { return hex(c.DealerID) }),
+			"stockNumber":  prop(graphql.String, func(c *model.Car) any { return optional(c.StockNumber) }),
+			"dealer": &graphql.Field{
+				Type:        userType,
+				Description: "The user who listed the car",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					car := source[model.Car](p.Source)
+					if car.DealerID.IsZero() {
+						return nil, nil
+					}
+					return loadersFrom(p.Context).users.load(p.Context, car.DealerID), nil
+				},
+			},
+			"averageRating": prop(graphql.NewNonNull(graphql.Float), func(c *model.Car) any { return c.RatingAvg }),
+			"ratingCount":   prop(graphql.NewNonNull(graphql.Int), func(c *model.Car) any { return c.RatingCount }),
+			"reviews": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(reviewType))),
+				Args: graphql.FieldConfigArgument{
+					"first": {Type: graphql.Int, DefaultValue: 10},
+					"sort":  {Type: reviewSort, DefaultValue: model.ReviewSortNewest},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					params := model.ReviewListParams{Page: 1, Limit: min(intArg(p.Args, "first"), maxReviews), Sort: stringArg(p.Args, "sort")}
+					return loadersFrom(p.Context).reviewsFor(params).load(p.Context, source[model.Car](p.Source).ID), nil
+				},
+			},
+			"isFavorite": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Whether the viewer has favorited the car; false when signed out",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if _, ok := viewer(p.Context); !ok {
+						return false, nil
+					}
+					return loadersFrom(p.Context).favorites.load(p.Context, source[model.Car](p.Source).ID), nil
+				},
+			},
+			"version":   prop(graphql.NewNonNull(graphql.Int), func(c *model.Car) any { return c.Version }),
+			"createdAt": prop(graphql.NewNonNull(graphql.DateTime), func(c *model.Car) any { return c.CreatedAt }),
+			"updatedAt": prop(graphql.NewNonNull(graphql.DateTime), func(c *model.Car) any { return c.UpdatedAt }),
+		},
+	})
+
+	favoriteType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Favorite",
+		Fields: graphql.Fields{
+			"id":           prop(graphql.NewNonNull(graphql.ID), func(f *model.FavoriteWithCar) any { return f.ID.Hex() }),
+			"car":          prop(carType, func(f *model.FavoriteWithCar) any { return f.Car }),
+			"collectionId": prop(graphql.ID, func(f *model.FavoriteWithCar) any { return hex(f.CollectionID) }),
+			"note":         prop(graphql.NewNonNull(graphql.String), func(f *model.FavoriteWithCar) any { return f.Note }),
+			"createdAt":    prop(graphql.NewNonNull(graphql.DateTime), func(f *model.FavoriteWithCar) any { return f.CreatedAt }),
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"car": &graphql.Field{
+				Type: carType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := objectID(p.Args["id"], "car")
+					if err != nil {
+						return nil, resolverError(p.Context, err)
+					}
+					return loadersFrom(p.Context).cars.load(p.Context, id), nil
+				},
+			},
+			"cars": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(carType))),
+				Args: graphql.FieldConfigArgument{
+					"make":         {Type: graphql.String},
+					"bodyType":     {Type: graphql.String},
+					"fuelType":     {Type: graphql.String},
+					"transmission": {Type: graphql.String},
+					"minPrice":     {Type: graphql.Float},
+					"maxPrice":     {Type: graphql.Float},
+					"minYear":      {Type: graphql.Int},
+					"maxYear":      {Type: graphql.Int},
+					"sort":         {Type: carSort},
+					"first":        {Type: graphql.Int, DefaultValue: defaultCars},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					filter := carFilter(p.Args)
+					filter.Limit = min(intArg(p.Args, "first"), maxCars)
+					if filter.Limit < 1 {
+						filter.Limit = defaultCars
+					}
+					cars, err := d.Cars.List(p.Context, filter)
+					if err != nil {
+						return nil, resolverError(p.Context, err)
+					}
+					return cars, nil
+				},
+			},
+			"review": &graphql.Field{
+				Type: reviewType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := objectID(p.Args["id"], "review")
+					if err != nil {
+						return nil, resolverError(p.Context, err)
+					}
+					review, err := d.Reviews.GetReviewByID(p.Context, id)
+					return found(p.Context, review, err)
+				},
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := objectID(p.Args["id"], "user")
+					if err != nil {
+						return nil, resolverError(p.Context, err)
+					}
+					return loadersFrom(p.Context).users.load(p.Context, id), nil
+				},
+			},
+			"me": &graphql.Field{
+				Type:        userType,
+				Description: "The signed-in user; null when signed out",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, ok := viewer(p.Context)
+					if !ok {
+						return nil, nil
+					}
+					return loadersFrom(p.Context).users.load(p.Context, id), nil
+				},
+			},
+			"favorites": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(favoriteType))),
+				Description: "The viewer's favorites",
+				Args: graphql.FieldConfigArgument{
+					"page":  {Type: graphql.Int, DefaultValue: 1},
+					"limit": {Type: graphql.Int, DefaultValue: 50},
+					"sort":  {Type: favoriteSort, DefaultValue: model.FavoriteSortNewest},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					userID, err := requireViewer(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					params := model.FavoriteListParams{Page: intArg(p.Args, "page"), Limit: min(intArg(p.Args, "limit"), maxFavorites), Sort: stringArg(p.Args, "sort")}
+					page, err := d.Favorites.GetUserFavorites(p.Context, userID, params)
+					if err != nil {
+						return nil, resolverError(p.Context, err)
+					}
+					return page.Favorites, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutations(d, carType, reviewType),
+	})
+}
+
+// mutations mirror the REST handlers of the same names; all need a signed-in user
+func mutations(d Deps, carType, reviewType *graphql.Object) *graphql.Object {
+	idArg := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+	versionArg := &graphql.ArgumentConfig{
+		Type:        graphql.Int,
+		Description: "The version the change is based on, like If-Match; omit to overwrite",
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createCar": mutation(graphql.NewNonNull(carType), graphql.FieldConfigArgument{
+				"input": {Type: graphql.NewNonNull(createCarInput)},
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				var input model.CreateCarInput
+				if err := decodeInput(args["input"], &input); err != nil {
+					return nil, err
+				}
+				car := input.NewCar()
+				car.DealerID = userID
+				if err := d.Cars.Create(ctx, car); err != nil {
+					return nil, err
+				}
+				metrics.CarsCreated.Inc()
+				return car, nil
+			}),
+			"updateCar": mutation(graphql.NewNonNull(carType), graphql.FieldConfigArgument{
+				"id":      idArg,
+				"input":   {Type: graphql.NewNonNull(updateCarInput)},
+				"version": versionArg,
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				var input model.UpdateCarInput
+				if err := decodeInput(args["input"], &input); err != nil {
+					return nil, err
+				}
+				if err := checkCarOwner(ctx, d.Cars, userID, args["id"].(string)); err != nil {
+					return nil, err
+				}
+				return d.Cars.Update(ctx, args["id"].(string), input, version(args))
+			}),
+			"patchCar": mutation(graphql.NewNonNull(carType), graphql.FieldConfigArgument{
+				"id":      idArg,
+				"input":   {Type: graphql.NewNonNull(patchCarInput)},
+				"version": versionArg,
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				var input model.PatchCarInput
+				if err := decodeInput(args["input"], &input); err != nil {
+					return nil, err
+				}
+				if err := checkCarOwner(ctx, d.Cars, userID, args["id"].(string)); err != nil {
+					return nil, err
+				}
+				return d.Cars.Patch(ctx, args["id"].(string), input, version(args))
+			}),
+			"deleteCar": mutation(graphql.NewNonNull(graphql.Boolean), graphql.FieldConfigArgument{
+				"id":      idArg,
+				"version": versionArg,
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				if err := checkCarOwner(ctx, d.Cars, userID, args["id"].(string)); err != nil {
+					return nil, err
+				}
+				if err := d.Cars.Delete(ctx, args["id"].(string), version(args)); err != nil {
+					return nil, err
+				}
+				return true, nil
+			}),
+
+			"addFavorite": mutation(carType, graphql.FieldConfigArgument{
+				"input": {Type: graphql.NewNonNull(favoriteInput)},
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				var input model.AddFavoriteInput
+				if err := decodeInput(args["input"], &input); err != nil {
+					return nil, err
+				}
+				favorite := &model.Favorite{UserID: userID, Note: input.Note}
+				favorite.CarID, _ = primitive.ObjectIDFromHex(input.CarID)
+				if input.CollectionID != "" {
+					favorite.CollectionID, _ = primitive.ObjectIDFromHex(input.CollectionID)
+				}
+				if err := d.Favorites.AddToFavorites(ctx, favorite); err != nil {
+					return nil, err
+				}
+				return loadersFrom(ctx).cars.load(ctx, favorite.CarID), nil
+			}),
+			"removeFavorite": mutation(carType, graphql.FieldConfigArgument{
+				"carId": idArg,
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				carID, err := objectID(args["carId"], "car")
+				if err != nil {
+					return nil, err
+				}
+				if err := d.Favorites.RemoveFromFavorites(ctx, userID, carID); err != nil {
+					return nil, err
+				}
+				return loadersFrom(ctx).cars.load(ctx, carID), nil
+			}),
+
+			"createReview": mutation(graphql.NewNonNull(reviewType), graphql.FieldConfigArgument{
+				"carId": idArg,
+				"input": {Type: graphql.NewNonNull(reviewInput)},
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				var input model.UpdateReviewInput
+				if err := decodeInput(args["input"], &input); err != nil {
+					return nil, err
+				}
+				carID, err := objectID(args["carId"], "car")
+				if err != nil {
+					return nil, err
+				}
+				user, err := d.Users.GetUserByID(ctx, userID)
+				if err != nil {
+					return nil, err
+				}
+				review := &model.Review{
+					CarID:    carID,
+					UserID:   userID,
+					Username: user.Username,
+					Rating:   input.Rating,
+					Comment:  input.Comment,
+				}
+				if err := d.Reviews.CreateReview(ctx, review); err != nil {
+					return nil, err
+				}
+				metrics.ReviewsPosted.Inc()
+				return review, nil
+			}),
+			"updateReview": mutation(graphql.NewNonNull(reviewType), graphql.FieldConfigArgument{
+				"id":      idArg,
+				"input":   {Type: graphql.NewNonNull(reviewInput)},
+				"version": versionArg,
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				var input model.UpdateReviewInput
+				if err := decodeInput(args["input"], &input); err != nil {
+					return nil, err
+				}
+				reviewID, err := objectID(args["id"], "review")
+				if err != nil {
+					return nil, err
+				}
+				return d.Reviews.UpdateReview(ctx, reviewID, userID, input, version(args))
+			}),
+			"deleteReview": mutation(graphql.NewNonNull(graphql.Boolean), graphql.FieldConfigArgument{
+				"id":      idArg,
+				"version": versionArg,
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				reviewID, err := objectID(args["id"], "review")
+				if err != nil {
+					return nil, err
+				}
+				if err := d.Reviews.DeleteReview(ctx, reviewID, userID, version(args)); err != nil {
+					return nil, err
+				}
+				return true, nil
+			}),
+			"voteReview": mutation(graphql.NewNonNull(reviewType), graphql.FieldConfigArgument{
+				"id":      idArg,
+				"helpful": {Type: graphql.NewNonNull(graphql.Boolean)},
+			}, func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error) {
+				reviewID, err := objectID(args["id"], "review")
+				if err != nil {
+					return nil, err
+				}
+				review, err := d.Reviews.GetReviewByID(ctx, reviewID)
+				if err != nil {
+					return nil, err
+				}
+				if review.UserID == userID {
+					return nil, domain.Forbidden("You cannot vote on your own review")
+				}
+				return d.Reviews.VoteReview(ctx, reviewID, userID, args["helpful"].(bool))
+			}),
+		},
+	})
+}
+
+// checkCarOwner fails unless the user listed the car or is an admin
+func checkCarOwner(ctx context.Context, cars repository.CarRepository, userID primitive.ObjectID, id string) error {
+	car, err := cars.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role, _ := ctx.Value(middleware.UserRoleKey).(string); car.DealerID != userID && role != "admin" {
+		return domain.Forbidden("You can only change your own cars")
+	}
+	return nil
+}
+
+// mutation is a field that needs a signed-in user and reports errors like the REST API
+func mutation(typ graphql.Output, args graphql.FieldConfigArgument, resolve func(ctx context.Context, userID primitive.ObjectID, args map[string]any) (any, error)) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Args: args,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			userID, err := requireViewer(p.Context)
+			if err != nil {
+				return nil, err
+			}
+			result, err := resolve(p.Context, userID, p.Args)
+			if err != nil {
+				return nil, resolverError(p.Context, err)
+			}
+			return result, nil
+		},
+	}
+}
+
+// private resolves a field only for the user it describes
+func private(typ graphql.Output, get func(*model.User) any) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			user := source[model.User](p.Source)
+			if id, ok := viewer(p.Context); !ok || id != user.ID {
+				return nil, nil
+			}
+			return get(user), nil
+		},
+	}
+}
+
+// found turns a not-found error into null, the GraphQL way of saying nothing is there
+func found[T any](ctx context.Context, v *T, err error) (any, error) {
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, resolverError(ctx, err)
+	}
+	return v, nil
+}
+
+func carFilter(args map[string]any) *model.FilterParams {
+	filter := &model.FilterParams{}
+	if v, ok := args["make"].(string); ok {
+		filter.Make = &v
+	}
+	if v, ok := args["bodyType"].(string); ok {
+		filter.BodyType = &v
+	}
+	if v, ok := args["fuelType"].(string); ok {
+		filter.FuelType = &v
+	}
+	if v, ok := args["transmission"].(string); ok {
+		filter.Transmission = &v
+	}
+	if v, ok := args["minPrice"].(float64); ok {
+		filter.MinPrice = &v
+	}
+	if v, ok := args["maxPrice"].(float64); ok {
+		filter.MaxPrice = &v
+	}
+	if v, ok := args["minYear"].(int); ok {
+		filter.MinYear = &v
+	}
+	if v, ok := args["maxYear"].(int); ok {
+		filter.MaxYear = &v
+	}
+	filter.Sort = stringArg(args, "sort")
+	return filter
+}
@@ -0,0 +1,119 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys a client authenticates with
+const (
+	authorizationKey = "authorization"
+	apiKeyKey        = "x-api-key"
+)
+
+// Caller is who made the call
+type Caller struct {
+	UserID primitive.ObjectID
+	Admin  bool
+}
+
+// owns reports whether the caller may change a car listed by dealerID
+func (c Caller) owns(dealerID primitive.ObjectID) bool {
+	return c.Admin || c.UserID == dealerID
+}
+
+type callerKey struct{}
+
+// CallerFrom returns the caller stored by the authentication interceptors
+func CallerFrom(ctx context.Context) (Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(Caller)
+	return c, ok
+}
+
+// authenticator identifies callers by JWT or API key
+type authenticator struct {
+	tokens *auth.Manager
+	// keys maps the hex SHA-256 of an API key to its dealer
+	keys map[string]primitive.ObjectID
+}
+
+func newAuthenticator(tokens *auth.Manager, apiKeys map[string]string) (*authenticator, error) {
+	a := &authenticator{tokens: tokens, keys: make(map[string]primitive.ObjectID, len(apiKeys))}
+	for digest, dealer := range apiKeys {
+		id, err := primitive.ObjectIDFromHex(dealer)
+		if err != nil {
+			return nil, err
+		}
+		a.keys[strings.ToLower(digest)] = id
+	}
+	return a, nil
+}
+
+// authenticate returns ctx with the Caller, or an Unauthenticated status.
+// An API key wins over a token when both are sent.
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if key := first(md.Get(apiKeyKey)); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		dealer, ok := a.keys[hex.EncodeToString(sum[:])]
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		return context.WithValue(ctx, callerKey{}, Caller{UserID: dealer}), nil
+	}
+
+	token, ok := strings.CutPrefix(first(md.Get(authorizationKey)), "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "send a bearer token in authorization or an API key in x-api-key")
+	}
+	claims, err := a.tokens.ValidateToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return context.WithValue(ctx, callerKey{}, Caller{UserID: userID, Admin: claims.Role == "admin"}), nil
+}
+
+func (a *authenticator) unary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream carries the Caller in its context
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context { return s.ctx }
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
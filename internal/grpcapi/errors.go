@@ -0,0 +1,64 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// codeFor maps domain errors to gRPC codes like problem.StatusFor maps them to HTTP statuses
+func codeFor(err error) codes.Code {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, domain.ErrConflict):
+		return codes.AlreadyExists
+	case errors.Is(err, domain.ErrInvalidID), errors.Is(err, domain.ErrValidation):
+		return codes.InvalidArgument
+	case errors.Is(err, domain.ErrForbidden):
+		return codes.PermissionDenied
+	case errors.Is(err, domain.ErrTooLarge):
+		return codes.ResourceExhausted
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return codes.FailedPrecondition
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	}
+	return codes.Internal
+}
+
+// statusError converts err to a gRPC status. Validation errors carry a
+// BadRequest detail with every invalid field; internal errors are logged and
+// hidden from the client.
+func statusError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codeFor(err)
+	if code == codes.Internal {
+		slog.ErrorContext(ctx, "grpc call failed", "error", err)
+		return status.Error(codes.Internal, "an unexpected error occurred")
+	}
+
+	var validationErr *domain.ValidationError
+	if !errors.As(err, &validationErr) {
+		return status.Error(code, err.Error())
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, f := range validationErr.Fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Message})
+	}
+	st, detailErr := status.New(code, err.Error()).WithDetails(badRequest)
+	if detailErr != nil {
+		return status.Error(code, err.Error())
+	}
+	return st.Err()
+}
@@ -0,0 +1,235 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/grpcapi/inventorypb"
+	"github.com/teamserik/online-car-store/internal/metrics"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/validate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	// maxStockNumber is the longest stock number accepted, in bytes
+	maxStockNumber = 64
+)
+
+// inventoryServer implements InventoryService over the car repository
+type inventoryServer struct {
+	inventorypb.UnimplementedInventoryServiceServer
+	cars         repository.CarRepository
+	maxSyncItems int
+}
+
+func (s *inventoryServer) CreateCar(ctx context.Context, req *inventorypb.CreateCarRequest) (*inventorypb.Car, error) {
+	caller, _ := CallerFrom(ctx)
+
+	input := createInput(req.GetCar())
+	stockNumber, err := checkStockNumber(req.GetCar().GetStockNumber(), false)
+	if err == nil {
+		err = validate.Struct(&input)
+	}
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	car := input.NewCar()
+	car.DealerID = caller.UserID
+	car.StockNumber = stockNumber
+	if err := s.cars.Create(ctx, car); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	metrics.CarsCreated.Inc()
+	return toProto(car), nil
+}
+
+func (s *inventoryServer) GetCar(ctx context.Context, req *inventorypb.GetCarRequest) (*inventorypb.Car, error) {
+	car, err := s.cars.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return toProto(car), nil
+}
+
+func (s *inventoryServer) ListCars(ctx context.Context, req *inventorypb.ListCarsRequest) (*inventorypb.ListCarsResponse, error) {
+	caller, _ := CallerFrom(ctx)
+
+	page, size := int(req.GetPage()), int(req.GetPageSize())
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = defaultPageSize
+	}
+	size = min(size, maxPageSize)
+
+	filter := &model.FilterParams{DealerID: &caller.UserID, Sort: model.CarSortNewest, Page: page, Limit: size}
+	cars, err := s.cars.List(ctx, filter)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	total, err := s.cars.Count(ctx, filter)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	resp := &inventorypb.ListCarsResponse{Total: total}
+	for _, car := range cars {
+		resp.Cars = append(resp.Cars, toProto(car))
+	}
+	return resp, nil
+}
+
+func (s *inventoryServer) UpdateCar(ctx context.Context, req *inventorypb.UpdateCarRequest) (*inventorypb.Car, error) {
+	if err := s.checkOwner(ctx, req.GetId()); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	input := updateInput(req.GetCar())
+	if err := validate.Struct(&input); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	car, err := s.cars.Update(ctx, req.GetId(), input, req.GetVersion())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return toProto(car), nil
+}
+
+func (s *inventoryServer) DeleteCar(ctx context.Context, req *inventorypb.DeleteCarRequest) (*inventorypb.DeleteCarResponse, error) {
+	if err := s.checkOwner(ctx, req.GetId()); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	if err := s.cars.Delete(ctx, req.GetId(), req.GetVersion()); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &inventorypb.DeleteCarResponse{}, nil
+}
+
+// SyncInventory saves each car as it arrives, so a stream that fails partway
+// leaves the cars before the failure saved; sending the stream again is safe
+func (s *inventoryServer) SyncInventory(stream inventorypb.InventoryService_SyncInventoryServer) error {
+	ctx := stream.Context()
+	caller, _ := CallerFrom(ctx)
+
+	resp := &inventorypb.SyncInventoryResponse{}
+	for index := 0; ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		if index >= s.maxSyncItems {
+			return status.Errorf(codes.ResourceExhausted, "a stream may send at most %d cars", s.maxSyncItems)
+		}
+
+		input := updateInput(req.GetCar())
+		stockNumber, err := checkStockNumber(req.GetCar().GetStockNumber(), true)
+		if err == nil {
+			err = validate.Struct(&input)
+		}
+		if err != nil {
+			resp.Errors = append(resp.Errors, &inventorypb.SyncError{Index: int32(index), StockNumber: req.GetCar().GetStockNumber(), Message: err.Error()})
+			continue
+		}
+
+		_, created, err := s.cars.UpsertByStockNumber(ctx, caller.UserID, stockNumber, input)
+		if err != nil {
+			return statusError(ctx, fmt.Errorf("sync %s: %w", stockNumber, err))
+		}
+		if created {
+			resp.Created++
+			metrics.CarsCreated.Inc()
+		} else {
+			resp.Updated++
+		}
+	}
+}
+
+// checkOwner fails unless the caller listed the car or is an admin
+func (s *inventoryServer) checkOwner(ctx context.Context, id string) error {
+	car, err := s.cars.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if caller, _ := CallerFrom(ctx); !caller.owns(car.DealerID) {
+		return domain.Forbidden("you can only change your own cars")
+	}
+	return nil
+}
+
+// checkStockNumber trims a stock number and checks its length
+func checkStockNumber(stockNumber string, required bool) (string, error) {
+	stockNumber = strings.TrimSpace(stockNumber)
+	switch {
+	case stockNumber == "" && required:
+		return "", domain.NewValidationError("stock_number", "is required")
+	case len(stockNumber) > maxStockNumber:
+		return "", domain.NewValidationError("stock_number", fmt.Sprintf("must be at most %d characters", maxStockNumber))
+	}
+	return stockNumber, nil
+}
+
+func createInput(in *inventorypb.CarInput) model.CreateCarInput {
+	return model.CreateCarInput(updateInput(in))
+}
+
+func updateInput(in *inventorypb.CarInput) model.UpdateCarInput {
+	return model.UpdateCarInput{
+		Make:         in.GetMake(),
+		Model:        in.GetModel(),
+		Year:         int(in.GetYear()),
+		Price:        in.GetPrice(),
+		Mileage:      int(in.GetMileage()),
+		BodyType:     in.GetBodyType(),
+		FuelType:     in.GetFuelType(),
+		Transmission: in.GetTransmission(),
+		Color:        in.GetColor(),
+		HorsePower:   int(in.GetHorsepower()),
+		EngineSize:   in.GetEngineSize(),
+		Description:  in.GetDescription(),
+		ImageURL:     in.GetImageUrl(),
+	}
+}
+
+func toProto(car *model.Car) *inventorypb.Car {
+	pb := &inventorypb.Car{
+		Id:           car.ID.Hex(),
+		StockNumber:  car.StockNumber,
+		Make:         car.Make,
+		Model:        car.Model,
+		Year:         int32(car.Year),
+		Price:        car.Price,
+		Mileage:      int32(car.Mileage),
+		BodyType:     car.BodyType,
+		FuelType:     car.FuelType,
+		Transmission: car.Transmission,
+		Color:        car.Color,
+		Horsepower:   int32(car.HorsePower),
+		EngineSize:   car.EngineSize,
+		Description:  car.Description,
+		ImageUrl:     car.ImageURL,
+		RatingAvg:    car.RatingAvg,
+		RatingCount:  int32(car.RatingCount),
+		Version:      car.Version,
+		CreatedAt:    timestamppb.New(car.CreatedAt),
+		UpdatedAt:    timestamppb.New(car.UpdatedAt),
+	}
+	if !car.DealerID.IsZero() {
+		pb.DealerId = car.DealerID.Hex()
+	}
+	return pb
+}
@@ -0,0 +1,271 @@
+package grpcapi_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/grpcapi"
+	"github.com/teamserik/online-car-store/internal/grpcapi/inventorypb"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const apiKey = "test-dealer-key"
+
+// TestInventory runs the service end to end over an in-memory connection:
+// authentication by token and API key, ownership of cars, paging,
+// SyncInventory upserting by stock number, and calls that panic
+func TestInventory(t *testing.T) {
+	cfg := config.Default()
+	cfg.GRPC.MaxSyncItems = 5
+
+	dealer, other := primitive.NewObjectID(), primitive.NewObjectID()
+	sum := sha256.Sum256([]byte(apiKey))
+	cfg.GRPC.APIKeys = map[string]string{hex.EncodeToString(sum[:]): dealer.Hex()}
+
+	tokens := auth.NewManager(cfg.Auth)
+	cars := repository.NewMemoryCarRepository()
+	client := dial(t, grpcapi.Deps{Cars: cars, Auth: tokens}, cfg.GRPC)
+
+	otherToken, err := tokens.GenerateToken(other, "other@example.com", "other", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminToken, err := tokens.GenerateToken(primitive.NewObjectID(), "admin@example.com", "admin", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withKey := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", apiKey)
+	asOther := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+otherToken)
+	asAdmin := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+adminToken)
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		ctxs := map[string]context.Context{
+			"no credentials": context.Background(),
+			"wrong API key":  metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "nope"),
+			"bad token":      metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer nope"),
+		}
+		for name, ctx := range ctxs {
+			if _, err := client.ListCars(ctx, &inventorypb.ListCarsRequest{}); status.Code(err) != codes.Unauthenticated {
+				t.Errorf("ListCars with %s: %v, want Unauthenticated", name, err)
+			}
+			stream, err := client.SyncInventory(ctx)
+			if err == nil {
+				_, err = stream.CloseAndRecv()
+			}
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("SyncInventory with %s: %v, want Unauthenticated", name, err)
+			}
+		}
+	})
+
+	t.Run("CRUD", func(t *testing.T) {
+		car, err := client.CreateCar(withKey, &inventorypb.CreateCarRequest{Car: carInput("C-1", 20000)})
+		if err != nil {
+			t.Fatalf("CreateCar: %v", err)
+		}
+		if car.DealerId != dealer.Hex() || car.StockNumber != "C-1" || car.Version != 1 {
+			t.Errorf("CreateCar = %v, want the API key's dealer and stock number", car)
+		}
+
+		if _, err := client.CreateCar(withKey, &inventorypb.CreateCarRequest{Car: carInput("C-1", 1)}); status.Code(err) != codes.AlreadyExists {
+			t.Errorf("CreateCar with a stock number in use: %v, want AlreadyExists", err)
+		}
+
+		got, err := client.GetCar(asOther, &inventorypb.GetCarRequest{Id: car.Id})
+		if err != nil || got.Make != "Toyota" {
+			t.Errorf("GetCar = %v, %v, want the car", got, err)
+		}
+
+		if _, err := client.UpdateCar(asOther, &inventorypb.UpdateCarRequest{Id: car.Id, Car: carInput("", 1)}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("UpdateCar of another dealer's car: %v, want PermissionDenied", err)
+		}
+		if _, err := client.UpdateCar(withKey, &inventorypb.UpdateCarRequest{Id: car.Id, Car: carInput("", 18000), Version: 5}); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("UpdateCar of a stale version: %v, want FailedPrecondition", err)
+		}
+		updated, err := client.UpdateCar(asAdmin, &inventorypb.UpdateCarRequest{Id: car.Id, Car: carInput("", 18000), Version: car.Version})
+		if err != nil || updated.Price != 18000 || updated.StockNumber != "C-1" {
+			t.Errorf("UpdateCar as admin = %v, %v, want the new price and the same stock number", updated, err)
+		}
+
+		if _, err := client.DeleteCar(asOther, &inventorypb.DeleteCarRequest{Id: car.Id}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("DeleteCar of another dealer's car: %v, want PermissionDenied", err)
+		}
+		if _, err := client.DeleteCar(withKey, &inventorypb.DeleteCarRequest{Id: car.Id}); err != nil {
+			t.Errorf("DeleteCar: %v", err)
+		}
+		if _, err := client.GetCar(withKey, &inventorypb.GetCarRequest{Id: car.Id}); status.Code(err) != codes.NotFound {
+			t.Errorf("GetCar after DeleteCar: %v, want NotFound", err)
+		}
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		input := carInput("", 1)
+		input.Year, input.BodyType = 1800, "Tank"
+		_, err := client.CreateCar(withKey, &inventorypb.CreateCarRequest{Car: input})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("CreateCar with invalid fields: %v, want InvalidArgument", err)
+		}
+		var fields []string
+		for _, detail := range status.Convert(err).Details() {
+			if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+				for _, v := range badRequest.FieldViolations {
+					fields = append(fields, v.Field)
+				}
+			}
+		}
+		if len(fields) != 2 {
+			t.Errorf("field violations %v, want year and body_type", fields)
+		}
+	})
+
+	t.Run("SyncInventory", func(t *testing.T) {
+		sync := func(items ...*inventorypb.CarInput) (*inventorypb.SyncInventoryResponse, error) {
+			stream, err := client.SyncInventory(withKey)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				if err := stream.Send(&inventorypb.SyncInventoryRequest{Car: item}); err != nil {
+					break // the server ended the stream; CloseAndRecv reports why
+				}
+			}
+			return stream.CloseAndRecv()
+		}
+
+		invalid := carInput("S-3", 1)
+		invalid.FuelType = "Steam"
+		resp, err := sync(carInput("S-1", 10000), carInput("S-2", 11000), carInput("", 1), invalid)
+		if err != nil {
+			t.Fatalf("SyncInventory: %v", err)
+		}
+		if resp.Created != 2 || resp.Updated != 0 || len(resp.Errors) != 2 || resp.Errors[0].Index != 2 || resp.Errors[1].StockNumber != "S-3" {
+			t.Errorf("first sync = %v, want 2 created and the 2 invalid items reported", resp)
+		}
+
+		resp, err = sync(carInput("S-1", 9500), carInput("S-4", 12000))
+		if err != nil {
+			t.Fatalf("SyncInventory: %v", err)
+		}
+		if resp.Created != 1 || resp.Updated != 1 || len(resp.Errors) != 0 {
+			t.Errorf("second sync = %v, want S-1 updated and S-4 created", resp)
+		}
+
+		list, err := client.ListCars(withKey, &inventorypb.ListCarsRequest{PageSize: 2})
+		if err != nil {
+			t.Fatalf("ListCars: %v", err)
+		}
+		if list.Total != 3 || len(list.Cars) != 2 {
+			t.Errorf("ListCars = %d of %d cars, want a page of 2 of the dealer's 3", len(list.Cars), list.Total)
+		}
+		for _, car := range list.Cars {
+			if car.StockNumber == "S-1" && (car.Price != 9500 || car.Version != 2) {
+				t.Errorf("S-1 = %v, want the synced price at version 2", car)
+			}
+		}
+
+		last, err := client.ListCars(withKey, &inventorypb.ListCarsRequest{Page: 2, PageSize: 2})
+		if err != nil || last.Total != 3 || len(last.Cars) != 1 || slices.ContainsFunc(list.Cars, func(c *inventorypb.Car) bool { return c.Id == last.Cars[0].Id }) {
+			t.Errorf("ListCars page 2 = %v, %v, want the one car not on page 1", last, err)
+		}
+
+		if others, err := client.ListCars(asOther, &inventorypb.ListCarsRequest{}); err != nil || others.Total != 0 {
+			t.Errorf("ListCars of another dealer = %v, %v, want none", others, err)
+		}
+
+		if _, err := sync(carInput("L-1", 1), carInput("L-2", 1), carInput("L-3", 1), carInput("L-4", 1), carInput("L-5", 1), carInput("L-6", 1)); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("SyncInventory over max_sync_items: %v, want ResourceExhausted", err)
+		}
+	})
+
+	t.Run("Panics", func(t *testing.T) {
+		client := dial(t, grpcapi.Deps{Cars: panickingCars{cars}, Auth: tokens}, cfg.GRPC)
+
+		if _, err := client.GetCar(withKey, &inventorypb.GetCarRequest{Id: primitive.NewObjectID().Hex()}); status.Code(err) != codes.Internal {
+			t.Errorf("GetCar that panics: %v, want Internal", err)
+		}
+		stream, err := client.SyncInventory(withKey)
+		if err == nil {
+			stream.Send(&inventorypb.SyncInventoryRequest{Car: carInput("P-1", 1)})
+			_, err = stream.CloseAndRecv()
+		}
+		if status.Code(err) != codes.Internal {
+			t.Errorf("SyncInventory that panics: %v, want Internal", err)
+		}
+		if _, err := client.ListCars(withKey, &inventorypb.ListCarsRequest{}); err != nil {
+			t.Errorf("ListCars after a panic: %v", err)
+		}
+	})
+}
+
+// panickingCars panics on the calls GetCar and SyncInventory make
+type panickingCars struct {
+	repository.CarRepository
+}
+
+func (panickingCars) GetByID(context.Context, string) (*model.Car, error) {
+	panic("GetByID")
+}
+
+func (panickingCars) UpsertByStockNumber(context.Context, primitive.ObjectID, string, model.UpdateCarInput) (*model.Car, bool, error) {
+	panic("UpsertByStockNumber")
+}
+
+// dial serves the inventory service over an in-memory listener
+func dial(t *testing.T, d grpcapi.Deps, cfg config.GRPC) inventorypb.InventoryServiceClient {
+	t.Helper()
+
+	srv, err := grpcapi.NewServer(d, cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		grpcapi.Serve(ctx, srv, lis, 0)
+		close(done)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-done
+	})
+	return inventorypb.NewInventoryServiceClient(conn)
+}
+
+func carInput(stockNumber string, price float64) *inventorypb.CarInput {
+	return &inventorypb.CarInput{
+		StockNumber:  stockNumber,
+		Make:         "Toyota",
+		Model:        "Corolla",
+		Year:         2020,
+		Price:        price,
+		BodyType:     "Sedan",
+		FuelType:     "Gasoline",
+		Transmission: "Automatic",
+		Color:        "White",
+	}
+}
@@ -0,0 +1,129 @@
+// Package grpcapi serves the dealer inventory gRPC service defined in
+// proto/carstore/inventory/v1, letting dealers push inventory in bulk instead
+// of calling the REST API once per car. It runs on its own port next to the
+// HTTP server and is backed by the same car repository.
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/teamserik/online-car-store --go-grpc_out=../.. --go-grpc_opt=module=github.com/teamserik/online-car-store carstore/inventory/v1/inventory.proto
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/grpcapi/inventorypb"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// Deps are what the service is built from
+type Deps struct {
+	Cars repository.CarRepository
+	Auth *auth.Manager
+}
+
+// NewServer returns a gRPC server with InventoryService registered. Every call
+// is authenticated and logged, and a panicking call fails with Internal instead
+// of taking the process down. creds may be nil to serve without TLS.
+func NewServer(d Deps, cfg config.GRPC, creds credentials.TransportCredentials) (*grpc.Server, error) {
+	authn, err := newAuthenticator(d.Auth, cfg.APIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(logUnary, recoverUnary, authn.unary),
+		grpc.ChainStreamInterceptor(logStream, recoverStream, authn.stream),
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	srv := grpc.NewServer(opts...)
+	inventorypb.RegisterInventoryServiceServer(srv, &inventoryServer{cars: d.Cars, maxSyncItems: cfg.MaxSyncItems})
+	return srv, nil
+}
+
+// Serve runs srv on lis until ctx is cancelled, then lets calls in flight
+// finish for up to drain before closing them. It is meant to run as a server.Worker.
+func Serve(ctx context.Context, srv *grpc.Server, lis net.Listener, drain time.Duration) {
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(lis) }()
+
+	select {
+	case err := <-served:
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			slog.Error("grpc server failed", "error", err)
+		}
+		return
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(drain):
+		srv.Stop()
+	}
+	<-served
+}
+
+func logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func logStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+// recoverUnary and recoverStream turn a panic into an Internal error: unlike
+// net/http, grpc-go does not recover handler panics, and the HTTP API shares the process
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ctx, info.FullMethod, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ss.Context(), info.FullMethod, p)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func recovered(ctx context.Context, method string, p any) error {
+	slog.ErrorContext(ctx, "grpc call panicked", "method", method, "panic", p, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "an unexpected error occurred")
+}
+
+// logCall writes one line per call, like the HTTP access log
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	slog.InfoContext(ctx, "grpc call",
+		"method", method,
+		"code", status.Code(err).String(),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+}
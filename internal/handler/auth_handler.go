@@ -1,55 +1,56 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/metrics"
+	"github.com/teamserik/online-car-store/internal/middleware"
 	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/problem"
 	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/validate"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func Register(userRepo repository.UserRepository) http.HandlerFunc {
+func Register(userRepo repository.UserRepository, authManager *auth.Manager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var input model.RegisterInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
-		if input.Email == "" || input.Password == "" || input.Username == "" {
-			http.Error(w, "Email, username and password are required", http.StatusBadRequest)
-			return
-		}
+		ctx := r.Context()
 
-		if len(input.Password) < 6 {
-			http.Error(w, "Password must be at least 6 characters", http.StatusBadRequest)
+		existingUser, err := userRepo.FindByEmail(ctx, input.Email)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			problem.Error(w, r, err)
 			return
 		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		existingUser, _ := userRepo.FindByEmail(ctx, input.Email)
 		if existingUser != nil {
-			http.Error(w, "Email already registered", http.StatusConflict)
+			problem.Write(w, r, http.StatusConflict, "Email already registered")
 			return
 		}
 
-		existingUser, _ = userRepo.FindByUsername(ctx, input.Username)
+		existingUser, err = userRepo.FindByUsername(ctx, input.Username)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			problem.Error(w, r, err)
+			return
+		}
 		if existingUser != nil {
-			http.Error(w, "Username already taken", http.StatusConflict)
+			problem.Write(w, r, http.StatusConflict, "Username already taken")
 			return
 		}
 
-		hashedPassword, err := auth.HashPassword(input.Password)
+		hashedPassword, err := authManager.HashPassword(input.Password)
 		if err != nil {
-			http.Error(w, "Error processing password", http.StatusInternalServerError)
+			slog.ErrorContext(ctx, "hash password", "error", err)
+			problem.Write(w, r, http.StatusInternalServerError, "Error processing password")
 			return
 		}
 
@@ -64,13 +65,15 @@ func Register(userRepo repository.UserRepository) http.HandlerFunc {
 		}
 
 		if err := userRepo.Create(ctx, user); err != nil {
-			http.Error(w, "Error creating user", http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
+		metrics.UsersRegistered.Inc()
 
-		token, err := auth.GenerateToken(user.ID, user.Email, user.Username, user.Role)
+		token, err := authManager.GenerateToken(user.ID, user.Email, user.Username, user.Role)
 		if err != nil {
-			http.Error(w, "Error generating token", http.StatusInternalServerError)
+			slog.ErrorContext(ctx, "generate token", "user_id", user.ID.Hex(), "error", err)
+			problem.Write(w, r, http.StatusInternalServerError, "Error generating token")
 			return
 		}
 
@@ -85,38 +88,38 @@ func Register(userRepo repository.UserRepository) http.HandlerFunc {
 	}
 }
 
-func Login(userRepo repository.UserRepository) http.HandlerFunc {
+func Login(userRepo repository.UserRepository, authManager *auth.Manager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var input model.LoginInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
-		// Изменено: теперь проверяем username вместо email
-		if input.Username == "" || input.Password == "" {
-			http.Error(w, "Username and password are required", http.StatusBadRequest)
-			return
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		ctx := r.Context()
 
 		// Изменено: ищем пользователя по username
 		user, err := userRepo.FindByUsername(ctx, input.Username)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			problem.Error(w, r, err)
+			return
+		}
 		if err != nil {
-			http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+			metrics.LoginsFailed.Inc()
+			problem.Write(w, r, http.StatusUnauthorized, "Invalid username or password")
 			return
 		}
 
 		if !auth.CheckPassword(input.Password, user.Password) {
-			http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+			metrics.LoginsFailed.Inc()
+			problem.Write(w, r, http.StatusUnauthorized, "Invalid username or password")
 			return
 		}
 
-		token, err := auth.GenerateToken(user.ID, user.Email, user.Username, user.Role)
+		token, err := authManager.GenerateToken(user.ID, user.Email, user.Username, user.Role)
 		if err != nil {
-			http.Error(w, "Error generating token", http.StatusInternalServerError)
+			slog.ErrorContext(ctx, "generate token", "user_id", user.ID.Hex(), "error", err)
+			problem.Write(w, r, http.StatusInternalServerError, "Error generating token")
 			return
 		}
 
@@ -132,24 +135,13 @@ func Login(userRepo repository.UserRepository) http.HandlerFunc {
 
 func GetProfile(userRepo repository.UserRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		claims, err := extractClaims(r)
-		if err != nil {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		userID := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
 
-		userID, err := primitive.ObjectIDFromHex(claims.UserID)
-		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
-			return
-		}
+		ctx := r.Context()
 
 		user, err := userRepo.FindByID(ctx, userID)
 		if err != nil {
-			http.Error(w, "User not found", http.StatusNotFound)
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -157,17 +149,3 @@ func GetProfile(userRepo repository.UserRepository) http.HandlerFunc {
 		json.NewEncoder(w).Encode(user)
 	}
 }
-
-func extractClaims(r *http.Request) (*auth.Claims, error) {
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		return nil, errors.New("no authorization header")
-	}
-
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return nil, errors.New("invalid authorization header")
-	}
-
-	return auth.ValidateToken(parts[1])
-}
@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/handler"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/problem"
+	"github.com/teamserik/online-car-store/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCancelledRequests checks that a request whose context ends while the
+// handler talks to a repository fails with 499 or 504, not a generic 500
+func TestCancelledRequests(t *testing.T) {
+	repos := repositorytest.NewMemoryRepos()
+	carID := primitive.NewObjectID().Hex()
+	reviewID := primitive.NewObjectID().Hex()
+
+	handlers := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		body    string
+		path    map[string]string
+	}{
+		{"ListCars", handler.ListCars(repos.Cars), http.MethodGet, "", nil},
+		{"GetCar", handler.GetCar(repos.Cars), http.MethodGet, "", map[string]string{"id": carID}},
+		{"GetProfile", handler.GetProfile(repos.Users), http.MethodGet, "", nil},
+		{"Register", handler.Register(repos.Users, auth.NewManager(config.Default().Auth)), http.MethodPost, `{"username":"ivan","email":"ivan@example.com","password":"secret1"}`, nil},
+		{"GetCarReviews", handler.GetCarReviews(repos.Reviews), http.MethodGet, "", map[string]string{"id": carID}},
+		{"CreateReview", handler.CreateReview(repos.Reviews, repos.Users), http.MethodPost, `{"rating":5,"comment":"Great car"}`, map[string]string{"id": carID}},
+		{"VoteReview", handler.VoteReview(repos.Reviews), http.MethodPost, `{"helpful":true}`, map[string]string{"reviewId": reviewID}},
+		{"DeleteReviewReply", handler.DeleteReviewReply(repos.Reviews), http.MethodDelete, "", map[string]string{"reviewId": reviewID}},
+		{"GetFavorites", handler.GetFavorites(repos.Favorites), http.MethodGet, "", nil},
+		{"GetCollections", handler.GetCollections(repos.Favorites), http.MethodGet, "", nil},
+		{"GetGarage", handler.GetGarage(repos.Garage), http.MethodGet, "", nil},
+		{"AddToGarage", handler.AddToGarage(repos.Garage, repos.Cars), http.MethodPost, `{"car_id":"` + carID + `"}`, nil},
+		{"GetNotifications", handler.GetNotifications(repos.Notifications), http.MethodGet, "", nil},
+	}
+
+	ends := []struct {
+		name   string
+		ctx    func() context.Context
+		status int
+	}{
+		{"Cancelled", func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}, problem.StatusClientClosedRequest},
+		{"DeadlineExceeded", func() context.Context {
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			t.Cleanup(cancel)
+			return ctx
+		}, http.StatusGatewayTimeout},
+	}
+
+	for _, h := range handlers {
+		for _, end := range ends {
+			t.Run(h.name+"/"+end.name, func(t *testing.T) {
+				ctx := context.WithValue(end.ctx(), middleware.UserIDKey, primitive.NewObjectID())
+				req := httptest.NewRequestWithContext(ctx, h.method, "/api/v1/test", strings.NewReader(h.body))
+				req.Header.Set("Content-Type", "application/json")
+				for key, value := range h.path {
+					req.SetPathValue(key, value)
+				}
+
+				rec := httptest.NewRecorder()
+				h.handler(rec, req)
+
+				if rec.Code != end.status {
+					t.Fatalf("status %d, want %d: %s", rec.Code, end.status, rec.Body)
+				}
+				var p problem.Problem
+				if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || p.Status != end.status {
+					t.Errorf("problem %+v, %v; want status %d", p, err, end.status)
+				}
+			})
+		}
+	}
+}
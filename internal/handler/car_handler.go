@@ -1,44 +1,40 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
-	"strings"
 
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/metrics"
+	"github.com/teamserik/online-car-store/internal/middleware"
 	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/problem"
 	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/validate"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func CreateCar(repo repository.CarRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var input model.CreateCarInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
-		car := &model.Car{
-			Make:         input.Make,
-			Model:        input.Model,
-			Year:         input.Year,
-			Price:        input.Price,
-			Mileage:      input.Mileage,
-			BodyType:     input.BodyType,
-			FuelType:     input.FuelType,
-			Transmission: input.Transmission,
-			Color:        input.Color,
-			HorsePower:   input.HorsePower,
-			EngineSize:   input.EngineSize,
-			Description:  input.Description,
-			ImageURL:     input.ImageURL,
+		car := input.NewCar()
+
+		// The user who lists the car is its dealer
+		if userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID); ok {
+			car.DealerID = userID
 		}
 
-		ctx := context.Background()
+		ctx := r.Context()
 		if err := repo.Create(ctx, car); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
+		metrics.CarsCreated.Inc()
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
@@ -65,11 +61,18 @@ func ListCars(repo repository.CarRepository) http.HandlerFunc {
 		if transmission := query.Get("transmission"); transmission != "" {
 			filter.Transmission = &transmission
 		}
+		if sort := query.Get("sort"); sort != "" {
+			if !model.ValidCarSort(sort) {
+				problem.Write(w, r, http.StatusBadRequest, "Invalid sort option")
+				return
+			}
+			filter.Sort = sort
+		}
 
-		ctx := context.Background()
+		ctx := r.Context()
 		cars, err := repo.List(ctx, &filter)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -80,48 +83,104 @@ func ListCars(repo repository.CarRepository) http.HandlerFunc {
 
 func GetCar(repo repository.CarRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id := strings.TrimPrefix(r.URL.Path, "/api/cars/")
+		id := r.PathValue("id")
 
-		ctx := context.Background()
+		ctx := r.Context()
 		car, err := repo.GetByID(ctx, id)
 		if err != nil {
-			http.Error(w, "Car not found", http.StatusNotFound)
+			problem.Error(w, r, err)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(car)
+		writeWithETag(w, r, versionETag(car.Version), car)
 	}
 }
 
+// UpdateCar handles PUT /api/cars/{id}. An If-Match header makes the write conditional.
 func UpdateCar(repo repository.CarRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id := strings.TrimPrefix(r.URL.Path, "/api/cars/")
+		id := r.PathValue("id")
+
+		version, err := ifMatchVersion(r, "car")
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
 
 		var input model.UpdateCarInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
-		ctx := context.Background()
-		if err := repo.Update(ctx, id, input); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		ctx := r.Context()
+		if err := checkCarOwner(r, repo, id); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		car, err := repo.Update(ctx, id, input, version)
+		if err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Car updated successfully"})
+		w.Header().Set("ETag", versionETag(car.Version))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(car)
+	}
+}
+
+// PatchCar handles PATCH /api/cars/{id}; only the fields in the body are changed
+func PatchCar(repo repository.CarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		version, err := ifMatchVersion(r, "car")
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		var input model.PatchCarInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+		if err := checkCarOwner(r, repo, id); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		car, err := repo.Patch(ctx, id, input, version)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("ETag", versionETag(car.Version))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(car)
 	}
 }
 
 func DeleteCar(repo repository.CarRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id := strings.TrimPrefix(r.URL.Path, "/api/cars/")
+		id := r.PathValue("id")
 
-		ctx := context.Background()
-		if err := repo.Delete(ctx, id); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		version, err := ifMatchVersion(r, "car")
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+		if err := checkCarOwner(r, repo, id); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		if err := repo.Delete(ctx, id, version); err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -129,3 +188,17 @@ func DeleteCar(repo repository.CarRepository) http.HandlerFunc {
 		json.NewEncoder(w).Encode(map[string]string{"message": "Car deleted successfully"})
 	}
 }
+
+// checkCarOwner fails unless the signed-in user listed the car or is an admin
+func checkCarOwner(r *http.Request, repo repository.CarRepository, id string) error {
+	car, err := repo.GetByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	userID, _ := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+	role, _ := r.Context().Value(middleware.UserRoleKey).(string)
+	if car.DealerID != userID && role != "admin" {
+		return domain.Forbidden("You can only change your own cars")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/handler"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCarOwnership checks that only the car's dealer or an admin may change
+// or delete it
+func TestCarOwnership(t *testing.T) {
+	repos := repositorytest.NewMemoryRepos()
+	dealer := primitive.NewObjectID()
+
+	handlers := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		body    string
+	}{
+		{"UpdateCar", handler.UpdateCar(repos.Cars), http.MethodPut, `{"make":"Lada","model":"Vesta","year":2021,"price":2,"body_type":"Sedan","fuel_type":"Gasoline","transmission":"Manual","color":"Red"}`},
+		{"PatchCar", handler.PatchCar(repos.Cars), http.MethodPatch, `{"price":3}`},
+		{"DeleteCar", handler.DeleteCar(repos.Cars), http.MethodDelete, ""},
+	}
+
+	callers := []struct {
+		name   string
+		userID primitive.ObjectID
+		role   string
+		status int
+	}{
+		{"OtherUser", primitive.NewObjectID(), "user", http.StatusForbidden},
+		{"Dealer", dealer, "user", http.StatusOK},
+		{"Admin", primitive.NewObjectID(), "admin", http.StatusOK},
+	}
+
+	for _, h := range handlers {
+		for _, caller := range callers {
+			t.Run(h.name+"/"+caller.name, func(t *testing.T) {
+				car := &model.Car{Make: "Lada", Model: "Vesta", Year: 2021, Price: 1, DealerID: dealer}
+				if err := repos.Cars.Create(context.Background(), car); err != nil {
+					t.Fatal(err)
+				}
+
+				ctx := context.WithValue(context.Background(), middleware.UserIDKey, caller.userID)
+				ctx = context.WithValue(ctx, middleware.UserRoleKey, caller.role)
+				req := httptest.NewRequestWithContext(ctx, h.method, "/api/v1/cars/"+car.ID.Hex(), strings.NewReader(h.body))
+				req.Header.Set("Content-Type", "application/json")
+				req.SetPathValue("id", car.ID.Hex())
+
+				rec := httptest.NewRecorder()
+				h.handler(rec, req)
+				if rec.Code != caller.status {
+					t.Errorf("status %d, want %d: %s", rec.Code, caller.status, rec.Body)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/problem"
+	"github.com/teamserik/online-car-store/internal/repository"
+)
+
+// versionETag returns the strong ETag for a resource version
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion returns the version named by If-Match, or repository.AnyVersion
+// when the header is absent or "*". Only a single strong version ETag can be checked
+// atomically, so anything else fails the precondition.
+func ifMatchVersion(r *http.Request, resource string) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return repository.AnyVersion, nil
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, domain.PreconditionFailed(resource)
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 1 {
+		return 0, domain.PreconditionFailed(resource)
+	}
+	return version, nil
+}
+
+// writeWithETag writes v as JSON with the given ETag, or 304 Not Modified
+// when the request's If-None-Match already names it
+func writeWithETag(w http.ResponseWriter, r *http.Request, etag string, v interface{}) {
+	w.Header().Set("ETag", etag)
+
+	if noneMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeWithContentETag writes v as JSON with a weak ETag derived from the body,
+// for responses such as lists that have no single version
+func writeWithContentETag(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if noneMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// noneMatch reports whether an If-None-Match header matches etag.
+// GET uses weak comparison, so W/ prefixes are ignored.
+func noneMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
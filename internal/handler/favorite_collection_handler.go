@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/problem"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/validate"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetCollections handles GET /api/favorites/collections
+func GetCollections(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		ctx := r.Context()
+
+		collections, err := favRepo.GetUserCollections(ctx, userID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(collections)
+	}
+}
+
+// CreateCollection handles POST /api/favorites/collections
+func CreateCollection(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var input model.CollectionInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		name := strings.TrimSpace(input.Name)
+
+		ctx := r.Context()
+
+		collection := &model.FavoriteCollection{
+			UserID: userID,
+			Name:   name,
+		}
+
+		if err := favRepo.CreateCollection(ctx, collection); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(collection)
+	}
+}
+
+// GetCollectionFavorites handles GET /api/favorites/collections/{collectionId}?page=1&limit=50&sort=newest
+func GetCollectionFavorites(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract collection ID from URL path
+		collectionID, err := primitive.ObjectIDFromHex(r.PathValue("collectionId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid collection ID")
+			return
+		}
+
+		params, err := parseFavoriteListParams(r)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		favorites, err := favRepo.GetCollectionFavorites(ctx, userID, collectionID, params)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Collection not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(favorites)
+	}
+}
+
+// RenameCollection handles PUT /api/favorites/collections/{collectionId}
+func RenameCollection(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract collection ID from URL path
+		collectionID, err := primitive.ObjectIDFromHex(r.PathValue("collectionId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid collection ID")
+			return
+		}
+
+		var input model.CollectionInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		name := strings.TrimSpace(input.Name)
+
+		ctx := r.Context()
+
+		if err := favRepo.RenameCollection(ctx, collectionID, userID, name); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Collection not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Collection renamed successfully",
+		})
+	}
+}
+
+// DeleteCollection handles DELETE /api/favorites/collections/{collectionId}
+func DeleteCollection(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract collection ID from URL path
+		collectionID, err := primitive.ObjectIDFromHex(r.PathValue("collectionId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid collection ID")
+			return
+		}
+
+		ctx := r.Context()
+
+		if err := favRepo.DeleteCollection(ctx, collectionID, userID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Collection not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Collection deleted successfully",
+		})
+	}
+}
+
+// ShareCollection handles POST /api/favorites/collections/{collectionId}/share
+func ShareCollection(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract collection ID from URL path
+		collectionID, err := primitive.ObjectIDFromHex(r.PathValue("collectionId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid collection ID")
+			return
+		}
+
+		ctx := r.Context()
+
+		token, err := favRepo.ShareCollection(ctx, collectionID, userID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Collection not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"share_token": token,
+			"url":         "/api/v1/shared/collections/" + token,
+		})
+	}
+}
+
+// UnshareCollection handles DELETE /api/favorites/collections/{collectionId}/share
+func UnshareCollection(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract collection ID from URL path
+		collectionID, err := primitive.ObjectIDFromHex(r.PathValue("collectionId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid collection ID")
+			return
+		}
+
+		ctx := r.Context()
+
+		if err := favRepo.UnshareCollection(ctx, collectionID, userID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Collection not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Collection is no longer shared",
+		})
+	}
+}
+
+// GetSharedCollection handles GET /api/shared/collections/{token} (no authentication)
+func GetSharedCollection(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.PathValue("token")
+
+		ctx := r.Context()
+
+		shared, err := favRepo.GetSharedCollection(ctx, token)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Collection not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(shared)
+	}
+}
@@ -1,15 +1,17 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strings"
-	"time"
+	"strconv"
 
+	"github.com/teamserik/online-car-store/internal/domain"
 	"github.com/teamserik/online-car-store/internal/middleware"
 	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/problem"
 	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/validate"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -19,29 +21,47 @@ func AddToFavorites(favRepo repository.FavoriteRepository) http.HandlerFunc {
 		// Get user ID from context (set by auth middleware)
 		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
 		if !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
 		var input model.AddFavoriteInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
 		// Validate car ID
 		carID, err := primitive.ObjectIDFromHex(input.CarID)
 		if err != nil {
-			http.Error(w, "Invalid car ID", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid car ID")
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		favorite := &model.Favorite{
+			UserID: userID,
+			CarID:  carID,
+			Note:   input.Note,
+		}
+
+		// Optionally put it straight into a collection
+		if input.CollectionID != "" {
+			favorite.CollectionID, err = primitive.ObjectIDFromHex(input.CollectionID)
+			if err != nil {
+				problem.Write(w, r, http.StatusBadRequest, "Invalid collection ID")
+				return
+			}
+		}
+
+		ctx := r.Context()
 
 		// Add to favorites
-		if err := favRepo.AddToFavorites(ctx, userID, carID); err != nil {
-			http.Error(w, "Failed to add to favorites", http.StatusInternalServerError)
+		if err := favRepo.AddToFavorites(ctx, favorite); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Collection not found")
+				return
+			}
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -52,22 +72,27 @@ func AddToFavorites(favRepo repository.FavoriteRepository) http.HandlerFunc {
 	}
 }
 
-// GetFavorites handles GET /api/favorites
+// GetFavorites handles GET /api/favorites?page=1&limit=50&sort=newest
 func GetFavorites(favRepo repository.FavoriteRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get user ID from context
 		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
 		if !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+		params, err := parseFavoriteListParams(r)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
 
-		favorites, err := favRepo.GetUserFavorites(ctx, userID)
+		ctx := r.Context()
+
+		favorites, err := favRepo.GetUserFavorites(ctx, userID, params)
 		if err != nil {
-			http.Error(w, "Failed to fetch favorites", http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -82,23 +107,21 @@ func RemoveFromFavorites(favRepo repository.FavoriteRepository) http.HandlerFunc
 		// Get user ID from context
 		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
 		if !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
 		// Extract car ID from URL path
-		path := strings.TrimPrefix(r.URL.Path, "/api/favorites/")
-		carID, err := primitive.ObjectIDFromHex(path)
+		carID, err := primitive.ObjectIDFromHex(r.PathValue("carId"))
 		if err != nil {
-			http.Error(w, "Invalid car ID", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid car ID")
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		ctx := r.Context()
 
 		if err := favRepo.RemoveFromFavorites(ctx, userID, carID); err != nil {
-			http.Error(w, "Failed to remove from favorites", http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -115,16 +138,15 @@ func GetFavoritesCount(favRepo repository.FavoriteRepository) http.HandlerFunc {
 		// Get user ID from context
 		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
 		if !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		ctx := r.Context()
 
 		count, err := favRepo.GetFavoritesCount(ctx, userID)
 		if err != nil {
-			http.Error(w, "Failed to get favorites count", http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -134,3 +156,123 @@ func GetFavoritesCount(favRepo repository.FavoriteRepository) http.HandlerFunc {
 		})
 	}
 }
+
+// MoveFavorite handles PUT /api/favorites/{carId}/collection
+func MoveFavorite(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract car ID from URL path
+		carID, err := primitive.ObjectIDFromHex(r.PathValue("carId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid car ID")
+			return
+		}
+
+		var input model.MoveFavoriteInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		// Empty collection ID moves the favorite out of its collection
+		var collectionID primitive.ObjectID
+		if input.CollectionID != "" {
+			collectionID, err = primitive.ObjectIDFromHex(input.CollectionID)
+			if err != nil {
+				problem.Write(w, r, http.StatusBadRequest, "Invalid collection ID")
+				return
+			}
+		}
+
+		ctx := r.Context()
+
+		if err := favRepo.MoveFavorite(ctx, userID, carID, collectionID); err != nil {
+			switch {
+			case errors.Is(err, repository.ErrCollectionNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Collection not found")
+			case errors.Is(err, domain.ErrNotFound):
+				problem.Write(w, r, http.StatusNotFound, "Favorite not found")
+			default:
+				problem.Error(w, r, err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Favorite moved successfully",
+		})
+	}
+}
+
+// UpdateFavoriteNote handles PUT /api/favorites/{carId}/note
+func UpdateFavoriteNote(favRepo repository.FavoriteRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract car ID from URL path
+		carID, err := primitive.ObjectIDFromHex(r.PathValue("carId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid car ID")
+			return
+		}
+
+		var input model.FavoriteNoteInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		if err := favRepo.UpdateFavoriteNote(ctx, userID, carID, input.Note); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Favorite not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Note updated successfully",
+		})
+	}
+}
+
+// parseFavoriteListParams reads page, limit and sort from the query string
+func parseFavoriteListParams(r *http.Request) (model.FavoriteListParams, error) {
+	query := r.URL.Query()
+	params := model.FavoriteListParams{Sort: query.Get("sort")}
+	errs := &domain.ValidationError{}
+
+	if params.Sort != "" && !model.ValidFavoriteSort(params.Sort) {
+		errs.Add("sort", "must be one of newest, oldest, price_asc, price_desc")
+	}
+
+	var err error
+	if page := query.Get("page"); page != "" {
+		if params.Page, err = strconv.Atoi(page); err != nil || params.Page < 1 {
+			errs.Add("page", "must be a positive integer")
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if params.Limit, err = strconv.Atoi(limit); err != nil || params.Limit < 1 {
+			errs.Add("limit", "must be a positive integer")
+		}
+	}
+
+	return params, errs.Err()
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/metrics"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/problem"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/validate"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetGarage handles GET /api/garage
+func GetGarage(garageRepo repository.GarageRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		ctx := r.Context()
+
+		garage, err := garageRepo.GetUserGarage(ctx, userID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(garage)
+	}
+}
+
+// AddToGarage handles POST /api/garage
+func AddToGarage(garageRepo repository.GarageRepository, carRepo repository.CarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var input model.AddGarageInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		// Remember the price at the time the car is added
+		car, err := carRepo.GetByID(ctx, input.CarID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		item := &model.GarageItem{
+			UserID:         userID,
+			CarID:          car.ID,
+			PriceWhenAdded: car.Price,
+		}
+
+		if err := garageRepo.AddToGarage(ctx, item); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		metrics.OrdersPlaced.Inc()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(item)
+	}
+}
+
+// RemoveFromGarage handles DELETE /api/garage/{carId}
+func RemoveFromGarage(garageRepo repository.GarageRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract car ID from URL path
+		carID, err := primitive.ObjectIDFromHex(r.PathValue("carId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid car ID")
+			return
+		}
+
+		ctx := r.Context()
+
+		if err := garageRepo.RemoveFromGarage(ctx, userID, carID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Car is not in your garage")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Removed from garage successfully",
+		})
+	}
+}
+
+// ImportGarage handles POST /api/garage/import
+// It merges the garage the browser kept in localStorage into the user's garage.
+func ImportGarage(garageRepo repository.GarageRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var input model.ImportGarageInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		// Skip malformed and duplicate entries from the client
+		seen := make(map[primitive.ObjectID]bool, len(input.Cars))
+		items := make([]model.GarageItem, 0, len(input.Cars))
+		for _, c := range input.Cars {
+			carID, err := primitive.ObjectIDFromHex(c.ID)
+			if err != nil || seen[carID] {
+				continue
+			}
+			seen[carID] = true
+
+			items = append(items, model.GarageItem{
+				UserID:         userID,
+				CarID:          carID,
+				PriceWhenAdded: c.Price,
+			})
+		}
+
+		ctx := r.Context()
+
+		imported, err := garageRepo.ImportGarage(ctx, userID, items)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		metrics.OrdersPlaced.Add(float64(imported))
+
+		garage, err := garageRepo.GetUserGarage(ctx, userID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"imported": imported,
+			"garage":   garage,
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/problem"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetNotifications handles GET /api/notifications?unread=true
+func GetNotifications(notificationRepo repository.NotificationRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		unreadOnly := r.URL.Query().Get("unread") == "true"
+
+		ctx := r.Context()
+
+		notifications, err := notificationRepo.GetUserNotifications(ctx, userID, unreadOnly)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(notifications)
+	}
+}
+
+// MarkNotificationRead handles POST /api/notifications/{notificationId}/read
+func MarkNotificationRead(notificationRepo repository.NotificationRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract notification ID from URL
+		notificationID, err := primitive.ObjectIDFromHex(r.PathValue("notificationId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid notification ID")
+			return
+		}
+
+		ctx := r.Context()
+
+		if err := notificationRepo.MarkAsRead(ctx, notificationID, userID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Notification not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Notification marked as read",
+		})
+	}
+}
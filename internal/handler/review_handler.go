@@ -1,83 +1,60 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
-	"strings"
-	"time"
+	"strconv"
 
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/metrics"
 	"github.com/teamserik/online-car-store/internal/middleware"
 	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/problem"
 	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/validate"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// CreateReview handles POST /api/reviews
+// CreateReview handles POST /api/reviews and POST /api/cars/{id}/reviews
 func CreateReview(reviewRepo repository.ReviewRepository, userRepo repository.UserRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get user ID from context
 		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
 		if !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
-		var carIDStr string
-		var rating int
-		var comment string
-
-		// Проверяем откуда приходит car_id
-		carIDQuery := r.URL.Query().Get("car_id")
-
-		if carIDQuery != "" {
-			// car_id пришел через query параметр
-			carIDStr = carIDQuery
-
-			// Читаем остальные данные из body
-			var input struct {
-				Rating  int    `json:"rating"`
-				Comment string `json:"comment"`
-			}
-			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-				http.Error(w, "Invalid request body", http.StatusBadRequest)
-				return
-			}
-			rating = input.Rating
-			comment = input.Comment
-		} else {
-			// Читаем все из body
-			var input model.CreateReviewInput
-			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-				http.Error(w, "Invalid request body", http.StatusBadRequest)
-				return
-			}
-			carIDStr = input.CarID
-			rating = input.Rating
-			comment = input.Comment
+		var input model.CreateReviewInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
 		}
 
-		// Validate rating
-		if rating < 1 || rating > 5 {
-			http.Error(w, "Rating must be between 1 and 5", http.StatusBadRequest)
+		// car_id из пути /api/cars/{id}/reviews имеет приоритет над body
+		if carID := r.PathValue("id"); carID != "" {
+			input.CarID = carID
+		}
+		if input.CarID == "" {
+			problem.Error(w, r, domain.NewValidationError("car_id", "is required"))
 			return
 		}
 
 		// Parse car ID
-		carObjectID, err := primitive.ObjectIDFromHex(carIDStr)
+		carObjectID, err := primitive.ObjectIDFromHex(input.CarID)
 		if err != nil {
-			http.Error(w, "Invalid car ID", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid car ID")
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		ctx := r.Context()
 
 		// Get username
 		user, err := userRepo.GetUserByID(ctx, userID)
 		if err != nil {
-			http.Error(w, "User not found", http.StatusNotFound)
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -85,14 +62,15 @@ func CreateReview(reviewRepo repository.ReviewRepository, userRepo repository.Us
 			CarID:    carObjectID,
 			UserID:   userID,
 			Username: user.Username,
-			Rating:   rating,
-			Comment:  comment,
+			Rating:   input.Rating,
+			Comment:  input.Comment,
 		}
 
 		if err := reviewRepo.CreateReview(ctx, review); err != nil {
-			http.Error(w, "Failed to create review", http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
+		metrics.ReviewsPosted.Inc()
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
@@ -100,33 +78,76 @@ func CreateReview(reviewRepo repository.ReviewRepository, userRepo repository.Us
 	}
 }
 
-// GetCarReviews handles GET /api/reviews?car_id=xxx
+// GetCarReviews handles GET /api/cars/{id}/reviews and GET /api/reviews?car_id=xxx,
+// both with &page=1&limit=10&sort=newest
 func GetCarReviews(reviewRepo repository.ReviewRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Get car_id from query parameters
-		carID := r.URL.Query().Get("car_id")
+		// Get the car from the path, or from query parameters
+		carID := r.PathValue("id")
 		if carID == "" {
-			http.Error(w, "car_id parameter is required", http.StatusBadRequest)
+			carID = r.URL.Query().Get("car_id")
+		}
+		if carID == "" {
+			problem.Write(w, r, http.StatusBadRequest, "car_id parameter is required")
 			return
 		}
 
 		carObjectID, err := primitive.ObjectIDFromHex(carID)
 		if err != nil {
-			http.Error(w, "Invalid car ID", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid car ID")
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+		query := r.URL.Query()
+		params := model.ReviewListParams{Sort: query.Get("sort")}
 
-		reviewsResponse, err := reviewRepo.GetCarReviews(ctx, carObjectID)
+		if params.Sort != "" && !model.ValidReviewSort(params.Sort) {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid sort option")
+			return
+		}
+		if page := query.Get("page"); page != "" {
+			if params.Page, err = strconv.Atoi(page); err != nil || params.Page < 1 {
+				problem.Write(w, r, http.StatusBadRequest, "Invalid page parameter")
+				return
+			}
+		}
+		if limit := query.Get("limit"); limit != "" {
+			if params.Limit, err = strconv.Atoi(limit); err != nil || params.Limit < 1 {
+				problem.Write(w, r, http.StatusBadRequest, "Invalid limit parameter")
+				return
+			}
+		}
+
+		ctx := r.Context()
+
+		reviewsResponse, err := reviewRepo.GetCarReviews(ctx, carObjectID, params)
 		if err != nil {
-			http.Error(w, "Failed to fetch reviews", http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(reviewsResponse)
+		writeWithContentETag(w, r, reviewsResponse)
+	}
+}
+
+// GetReview handles GET /api/reviews/{reviewId}
+func GetReview(reviewRepo repository.ReviewRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reviewID, err := primitive.ObjectIDFromHex(r.PathValue("reviewId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid review ID")
+			return
+		}
+
+		ctx := r.Context()
+
+		review, err := reviewRepo.GetReviewByID(ctx, reviewID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		writeWithETag(w, r, versionETag(review.Version), review)
 	}
 }
 
@@ -136,47 +157,44 @@ func UpdateReview(reviewRepo repository.ReviewRepository) http.HandlerFunc {
 		// Get user ID from context
 		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
 		if !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
 		// Extract review ID from URL
-		path := strings.TrimPrefix(r.URL.Path, "/api/reviews/")
-		reviewID, err := primitive.ObjectIDFromHex(path)
+		reviewID, err := primitive.ObjectIDFromHex(r.PathValue("reviewId"))
 		if err != nil {
-			http.Error(w, "Invalid review ID", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid review ID")
 			return
 		}
 
-		var input model.UpdateReviewInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		version, err := ifMatchVersion(r, "review")
+		if err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
-		// Validate rating
-		if input.Rating < 1 || input.Rating > 5 {
-			http.Error(w, "Rating must be between 1 and 5", http.StatusBadRequest)
+		var input model.UpdateReviewInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		ctx := r.Context()
 
-		err = reviewRepo.UpdateReview(ctx, reviewID, userID, input)
+		review, err := reviewRepo.UpdateReview(ctx, reviewID, userID, input, version)
 		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				http.Error(w, "Review not found or you don't have permission", http.StatusNotFound)
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Review not found or you don't have permission")
 				return
 			}
-			http.Error(w, "Failed to update review", http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
 
+		w.Header().Set("ETag", versionETag(review.Version))
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "Review updated successfully",
-		})
+		json.NewEncoder(w).Encode(review)
 	}
 }
 
@@ -186,28 +204,32 @@ func DeleteReview(reviewRepo repository.ReviewRepository) http.HandlerFunc {
 		// Get user ID from context
 		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
 		if !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
 		// Extract review ID from URL
-		path := strings.TrimPrefix(r.URL.Path, "/api/reviews/")
-		reviewID, err := primitive.ObjectIDFromHex(path)
+		reviewID, err := primitive.ObjectIDFromHex(r.PathValue("reviewId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid review ID")
+			return
+		}
+
+		version, err := ifMatchVersion(r, "review")
 		if err != nil {
-			http.Error(w, "Invalid review ID", http.StatusBadRequest)
+			problem.Error(w, r, err)
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		ctx := r.Context()
 
-		err = reviewRepo.DeleteReview(ctx, reviewID, userID)
+		err = reviewRepo.DeleteReview(ctx, reviewID, userID, version)
 		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				http.Error(w, "Review not found or you don't have permission", http.StatusNotFound)
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Review not found or you don't have permission")
 				return
 			}
-			http.Error(w, "Failed to delete review", http.StatusInternalServerError)
+			problem.Error(w, r, err)
 			return
 		}
 
@@ -217,3 +239,182 @@ func DeleteReview(reviewRepo repository.ReviewRepository) http.HandlerFunc {
 		})
 	}
 }
+
+// VoteReview handles POST /api/reviews/{reviewId}/vote
+func VoteReview(reviewRepo repository.ReviewRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Extract review ID from URL
+		reviewID, err := primitive.ObjectIDFromHex(r.PathValue("reviewId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid review ID")
+			return
+		}
+
+		var input model.VoteReviewInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		review, err := reviewRepo.GetReviewByID(ctx, reviewID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		if review.UserID == userID {
+			problem.Write(w, r, http.StatusForbidden, "You cannot vote on your own review")
+			return
+		}
+
+		review, err = reviewRepo.VoteReview(ctx, reviewID, userID, input.Helpful)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(review)
+	}
+}
+
+// ReplyToReview handles POST /api/reviews/{reviewId}/reply
+func ReplyToReview(reviewRepo repository.ReviewRepository, carRepo repository.CarRepository, notificationRepo repository.NotificationRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		role, _ := r.Context().Value(middleware.UserRoleKey).(string)
+		username, _ := r.Context().Value(middleware.UsernameKey).(string)
+
+		// Extract review ID from URL
+		reviewID, err := primitive.ObjectIDFromHex(r.PathValue("reviewId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid review ID")
+			return
+		}
+
+		var input model.ReplyReviewInput
+		if err := validate.Decode(w, r, &input); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		review, err := reviewRepo.GetReviewByID(ctx, reviewID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+
+		// Only the listing's dealer or an admin may reply
+		car, err := carRepo.GetByID(ctx, review.CarID.Hex())
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		if car.DealerID != userID && role != "admin" {
+			problem.Write(w, r, http.StatusForbidden, "Only the dealer of this car or an admin can reply")
+			return
+		}
+
+		reply := &model.ReviewReply{
+			UserID:   userID,
+			Username: username,
+			Role:     role,
+			Comment:  input.Comment,
+		}
+
+		if err := reviewRepo.ReplyToReview(ctx, reviewID, reply); err != nil {
+			if errors.Is(err, domain.ErrConflict) {
+				problem.Write(w, r, http.StatusConflict, "Review already has a reply")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		// Let the reviewer know; the reply itself is already saved
+		notification := &model.Notification{
+			UserID:   review.UserID,
+			Type:     model.NotificationReviewReply,
+			Message:  username + " replied to your review of " + car.Make + " " + car.Model,
+			CarID:    car.ID,
+			ReviewID: review.ID,
+		}
+		if err := notificationRepo.CreateNotification(ctx, notification); err != nil {
+			slog.WarnContext(ctx, "notify reviewer of reply", "review_id", review.ID.Hex(), "error", err)
+		}
+
+		review.Reply = reply
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}
+}
+
+// DeleteReviewReply handles DELETE /api/reviews/{reviewId}/reply
+func DeleteReviewReply(reviewRepo repository.ReviewRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID, ok := r.Context().Value(middleware.UserIDKey).(primitive.ObjectID)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		role, _ := r.Context().Value(middleware.UserRoleKey).(string)
+
+		// Extract review ID from URL
+		reviewID, err := primitive.ObjectIDFromHex(r.PathValue("reviewId"))
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid review ID")
+			return
+		}
+
+		ctx := r.Context()
+
+		review, err := reviewRepo.GetReviewByID(ctx, reviewID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		if review.Reply == nil {
+			problem.Write(w, r, http.StatusNotFound, "Reply not found")
+			return
+		}
+
+		// Only the author of the reply or an admin may delete it
+		if review.Reply.UserID != userID && role != "admin" {
+			problem.Write(w, r, http.StatusForbidden, "You don't have permission to delete this reply")
+			return
+		}
+
+		if err := reviewRepo.DeleteReply(ctx, reviewID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, http.StatusNotFound, "Reply not found")
+				return
+			}
+			problem.Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Reply deleted successfully",
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package health tracks whether the service and its dependencies can serve
+// traffic, and collects the details shown on the admin status page.
+package health
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Version is the release version, set at build time with
+// -ldflags "-X github.com/teamserik/online-car-store/internal/health.Version=1.2.3"
+var Version = "dev"
+
+// Check reports an error when a dependency is unusable
+type Check func(ctx context.Context) error
+
+// Detail returns extra information for the status page
+type Detail func(ctx context.Context) (any, error)
+
+// Result is the outcome of one readiness check
+type Result struct {
+	Name     string `json:"name"`
+	OK       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Build describes the running binary
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Status is the full report behind the admin status page
+type Status struct {
+	Build     Build          `json:"build"`
+	StartedAt time.Time      `json:"started_at"`
+	Uptime    string         `json:"uptime"`
+	Ready     bool           `json:"ready"`
+	Checks    []Result       `json:"checks"`
+	Details   map[string]any `json:"details"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs registered checks, each bounded by a timeout
+type Checker struct {
+	timeout   time.Duration
+	startedAt time.Time
+	stopping  atomic.Bool
+
+	mu      sync.RWMutex
+	checks  []namedCheck
+	details map[string]Detail
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout:   timeout,
+		startedAt: time.Now(),
+		details:   map[string]Detail{},
+	}
+}
+
+// Register adds a readiness check
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// RegisterDetail adds a section to the status page
+func (c *Checker) RegisterDetail(name string, detail Detail) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.details[name] = detail
+}
+
+// ShuttingDown makes readiness fail from now on, so load balancers stop
+// sending traffic while in-flight requests drain
+func (c *Checker) ShuttingDown() {
+	c.stopping.Store(true)
+}
+
+// Ready runs every check concurrently and reports whether all passed
+func (c *Checker) Ready(ctx context.Context) (bool, []Result) {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, nc)
+		}()
+	}
+	wg.Wait()
+
+	ready := !c.stopping.Load()
+	if !ready {
+		results = append(results, Result{Name: "shutdown", OK: false, Error: "server is shutting down", Duration: "0s"})
+	}
+	for _, result := range results {
+		ready = ready && result.OK
+	}
+	return ready, results
+}
+
+func (c *Checker) run(ctx context.Context, nc namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := nc.check(ctx)
+	result := Result{Name: nc.name, OK: err == nil, Duration: time.Since(start).Round(time.Microsecond).String()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Status gathers readiness, build information and every registered detail.
+// A detail that fails is reported as its error message.
+func (c *Checker) Status(ctx context.Context) Status {
+	ready, results := c.Ready(ctx)
+
+	c.mu.RLock()
+	sources := make(map[string]Detail, len(c.details))
+	for name, detail := range c.details {
+		sources[name] = detail
+	}
+	c.mu.RUnlock()
+
+	details := make(map[string]any, len(sources))
+	for name, detail := range sources {
+		detailCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		value, err := detail(detailCtx)
+		cancel()
+		if err != nil {
+			value = map[string]string{"error": err.Error()}
+		}
+		details[name] = value
+	}
+
+	return Status{
+		Build:     buildInfo(),
+		StartedAt: c.startedAt,
+		Uptime:    time.Since(c.startedAt).Round(time.Second).String(),
+		Ready:     ready,
+		Checks:    results,
+		Details:   details,
+	}
+}
+
+func buildInfo() Build {
+	build := Build{Version: Version}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	build.GoVersion = info.GoVersion
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			build.Commit = setting.Value
+		}
+	}
+	return build
+}
@@ -0,0 +1,121 @@
+// Package logging configures the process-wide slog logger and carries
+// request-scoped fields, such as the request ID, through contexts so that
+// every log line written while serving a request can be correlated.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/teamserik/online-car-store/internal/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New builds a logger writing to w in the configured format and level.
+// Records logged with a request context get request_id and user_id attributes,
+// plus trace_id and span_id when the request is traced.
+func New(cfg config.Log, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(cfg.Level)}
+
+	var handler slog.Handler
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// ParseLevel maps debug, info, warn and error to slog levels; anything else is info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// contextHandler adds the request fields stored in the record's context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if info := requestInfoFrom(ctx); info != nil {
+		record.AddAttrs(slog.String("request_id", info.id))
+		if userID := info.UserID(); userID != "" {
+			record.AddAttrs(slog.String("user_id", userID))
+		}
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type contextKey struct{}
+
+// requestInfo is shared by every context derived from the request's, so the
+// user ID set by authentication is visible to middleware that ran before it
+type requestInfo struct {
+	id string
+
+	mu     sync.Mutex
+	userID string
+}
+
+func (i *requestInfo) UserID() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.userID
+}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestInfo{id: id})
+}
+
+// RequestID returns the request ID stored in ctx, or ""
+func RequestID(ctx context.Context) string {
+	if info := requestInfoFrom(ctx); info != nil {
+		return info.id
+	}
+	return ""
+}
+
+// SetUserID records the authenticated user for the request in ctx
+func SetUserID(ctx context.Context, userID string) {
+	if info := requestInfoFrom(ctx); info != nil {
+		info.mu.Lock()
+		info.userID = userID
+		info.mu.Unlock()
+	}
+}
+
+// UserID returns the user recorded by SetUserID, or ""
+func UserID(ctx context.Context) string {
+	if info := requestInfoFrom(ctx); info != nil {
+		return info.UserID()
+	}
+	return ""
+}
+
+func requestInfoFrom(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(contextKey{}).(*requestInfo)
+	return info
+}
@@ -0,0 +1,115 @@
+// Package metrics defines the Prometheus metrics the service exports and the
+// registry they are served from.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "carstore"
+
+// Registry holds every metric below plus Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// HTTP
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	HTTPInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+)
+
+// MongoDB
+var (
+	MongoCommandDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_duration_seconds",
+		Help:      "MongoDB command latency by command name and outcome.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"command", "outcome"})
+
+	MongoConnectionsOpen = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mongo_pool_connections_open",
+		Help:      "Connections currently open in the MongoDB pool.",
+	})
+
+	MongoConnectionsInUse = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mongo_pool_connections_in_use",
+		Help:      "Connections currently checked out of the MongoDB pool.",
+	})
+
+	MongoCheckoutFailures = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mongo_pool_checkout_failures_total",
+		Help:      "Failed attempts to check a connection out of the MongoDB pool.",
+	})
+)
+
+// Business events. The store has no checkout, so an order is a car added to a
+// user's garage, the list they keep of cars they mean to buy.
+var (
+	UsersRegistered = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_registered_total",
+		Help:      "Accounts created.",
+	})
+
+	LoginsFailed = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_failed_total",
+		Help:      "Login attempts rejected for a wrong username or password.",
+	})
+
+	CarsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cars_created_total",
+		Help:      "Car listings created.",
+	})
+
+	OrdersPlaced = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_placed_total",
+		Help:      "Cars added to a garage, directly or imported from the browser.",
+	})
+
+	ReviewsPosted = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviews_posted_total",
+		Help:      "Reviews posted.",
+	})
+)
+
+// Handler serves Registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
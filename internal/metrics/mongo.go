@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// CommandMonitor records the duration of every MongoDB command
+func CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			MongoCommandDuration.WithLabelValues(e.CommandName, "success").Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			MongoCommandDuration.WithLabelValues(e.CommandName, "failure").Observe(e.Duration.Seconds())
+		},
+	}
+}
+
+// PoolMonitor tracks open and checked-out connections in the driver's pool
+func PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				MongoConnectionsOpen.Inc()
+			case event.ConnectionClosed:
+				MongoConnectionsOpen.Dec()
+			case event.GetSucceeded:
+				MongoConnectionsInUse.Inc()
+			case event.ConnectionReturned:
+				MongoConnectionsInUse.Dec()
+			case event.GetFailed:
+				MongoCheckoutFailures.Inc()
+			}
+		},
+	}
+}
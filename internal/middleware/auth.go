@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/logging"
+	"github.com/teamserik/online-car-store/internal/problem"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type contextKey string
+
+const (
+	// UserIDKey holds the authenticated user's primitive.ObjectID
+	UserIDKey contextKey = "user_id"
+	// UserRoleKey holds the authenticated user's role ("user", "dealer", "admin")
+	UserRoleKey contextKey = "user_role"
+	// UsernameKey holds the authenticated user's username
+	UsernameKey contextKey = "username"
+)
+
+// Authenticate returns middleware that validates the Bearer token and stores the user's identity in the request context
+func Authenticate(tokens *auth.Manager) func(next http.HandlerFunc) http.HandlerFunc {
+	return authenticate(tokens, true)
+}
+
+// OptionalAuthenticate is Authenticate for routes that also serve anonymous users:
+// a request without an Authorization header passes through unauthenticated
+func OptionalAuthenticate(tokens *auth.Manager) func(next http.HandlerFunc) http.HandlerFunc {
+	return authenticate(tokens, false)
+}
+
+func authenticate(tokens *auth.Manager, required bool) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" && !required {
+				next(w, r)
+				return
+			}
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				problem.Write(w, r, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			claims, err := tokens.ValidateToken(parts[1])
+			if err != nil {
+				problem.Write(w, r, http.StatusUnauthorized, "Invalid or expired token")
+				return
+			}
+
+			userID, err := primitive.ObjectIDFromHex(claims.UserID)
+			if err != nil {
+				problem.Write(w, r, http.StatusUnauthorized, "Invalid token")
+				return
+			}
+
+			logging.SetUserID(r.Context(), claims.UserID)
+
+			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+			ctx = context.WithValue(ctx, UsernameKey, claims.Username)
+
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// RequireRole rejects requests whose authenticated user does not have role.
+// It must run after Authenticate.
+func RequireRole(role string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if userRole, _ := r.Context().Value(UserRoleKey).(string); userRole != role {
+				problem.Write(w, r, http.StatusForbidden, "Insufficient permissions")
+				return
+			}
+			next(w, r)
+		}
+	}
+}
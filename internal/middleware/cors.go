@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/problem"
+)
+
+// preflightMethods are the methods a preflight may ask about
+var preflightMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// CORS lets the configured origins call the API from the browser. A preflight
+// is answered with the methods mux actually routes for its path, so browsers
+// learn about a missing route or method before sending the real request.
+// Requests without an Origin, and origins not on the list, get no CORS headers.
+func CORS(cfg config.CORS, mux *http.ServeMux) func(next http.Handler) http.Handler {
+	allowAny := slices.Contains(cfg.AllowedOrigins, "*")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			allowed := origin != "" && (allowAny || slices.Contains(cfg.AllowedOrigins, origin))
+			if allowed {
+				if allowAny && !cfg.AllowCredentials {
+					h.Set("Access-Control-Allow-Origin", "*")
+				} else {
+					h.Set("Access-Control-Allow-Origin", origin)
+				}
+				if cfg.AllowCredentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			requested := r.Header.Get("Access-Control-Request-Method")
+			if r.Method != http.MethodOptions || origin == "" || requested == "" {
+				if allowed && exposedHeaders != "" {
+					h.Set("Access-Control-Expose-Headers", exposedHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if !allowed {
+				problem.Write(w, r, http.StatusForbidden, "Origin not allowed")
+				return
+			}
+
+			methods := routeMethods(mux, r)
+			if len(methods) == 0 {
+				problem.Write(w, r, http.StatusNotFound, "Not found")
+				return
+			}
+			if !slices.Contains(methods, requested) {
+				h.Set("Allow", strings.Join(methods, ", "))
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Method not allowed")
+				return
+			}
+
+			h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			if allowedHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", allowedHeaders)
+			}
+			h.Set("Access-Control-Max-Age", maxAge)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// routeMethods lists the methods mux has a route for at r's path
+func routeMethods(mux *http.ServeMux, r *http.Request) []string {
+	var methods []string
+	for _, method := range preflightMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
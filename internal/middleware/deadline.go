@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/teamserik/online-car-store/internal/config"
+)
+
+// Deadline cancels the request context after the route's configured timeout,
+// which aborts any repository call still running. The route is the pattern mux
+// would dispatch to, looked up before the request reaches it.
+func Deadline(cfg config.Deadlines, mux *http.ServeMux) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pattern := mux.Handler(r)
+
+			timeout, ok := cfg.Routes[pattern]
+			if !ok {
+				timeout = cfg.Default
+			}
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/config"
+)
+
+// versionSegment matches the version right after /api/, e.g. /api/v1/
+var versionSegment = regexp.MustCompile(`^/api/v[0-9]+(/|$)`)
+
+// LegacyAPI serves the unversioned /api paths as aliases of /api/v1 by
+// rewriting the path before anything looks up the route, so deadlines, rate
+// limits and metrics treat both the same. Aliased responses carry Deprecation,
+// Sunset and a successor-version Link. Paths mux routes as they are, such as
+// unversioned documents, are left alone.
+func LegacyAPI(cfg config.API, mux *http.ServeMux) (func(next http.Handler) http.Handler, error) {
+	deprecated, sunset, err := cfg.LegacyDates()
+	if err != nil {
+		return nil, err
+	}
+	deprecation := "@" + strconv.FormatInt(deprecated.Unix(), 10)
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, "/api/") || versionSegment.MatchString(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, pattern := mux.Handler(r); strings.Contains(pattern, "/api/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			u := *r.URL
+			u.Path = "/api/v1" + strings.TrimPrefix(r.URL.Path, "/api")
+			if r.URL.RawPath != "" {
+				u.RawPath = "/api/v1" + strings.TrimPrefix(r.URL.RawPath, "/api")
+			}
+			aliased := new(http.Request)
+			*aliased = *r
+			aliased.URL = &u
+
+			h := w.Header()
+			h.Set("Deprecation", deprecation)
+			h.Set("Sunset", sunsetDate)
+			h.Add("Link", "<"+u.EscapedPath()+`>; rel="successor-version"`)
+			next.ServeHTTP(w, aliased)
+		})
+	}, nil
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/metrics"
+)
+
+// Metrics records request counts and latency per route. It must wrap the
+// ServeMux directly: the route label is the mux pattern that matched, which
+// keeps label cardinality bounded no matter which IDs appear in paths.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		start := time.Now()
+		rec := NewStatusRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		route := routeOf(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		method := methodLabel(r.Method)
+		status := strconv.Itoa(rec.Status)
+		metrics.HTTPRequests.WithLabelValues(route, method, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// methodLabel is method if it is a standard HTTP method and "OTHER" otherwise,
+// so clients cannot add label values by sending made-up methods
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
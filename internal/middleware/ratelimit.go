@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/problem"
+	"github.com/teamserik/online-car-store/internal/ratelimit"
+)
+
+// RateLimit throttles /api and /graphql requests with token buckets kept in store. Routes
+// listed in cfg.Routes, keyed by ServeMux pattern such as "POST /api/reviews",
+// get their own policy and bucket; everything else shares the default policy. Clients are
+// identified by user ID when they send a valid token and by IP otherwise.
+// Store errors let the request through rather than failing the API.
+func RateLimit(cfg config.RateLimit, store ratelimit.Store, tokens *auth.Manager, mux *http.ServeMux) (func(next http.Handler) http.Handler, error) {
+	defaultPolicy := ratelimit.PerMinute(cfg.RequestsPerMinute, cfg.Burst)
+
+	routes := make(map[string]ratelimit.Policy, len(cfg.Routes))
+	for route, spec := range cfg.Routes {
+		policy, err := ratelimit.ParsePolicy(spec)
+		if err != nil {
+			return nil, err
+		}
+		routes[route] = policy
+	}
+
+	trusted, err := ratelimit.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isAPI(r.URL.Path) || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			_, pattern := mux.Handler(r)
+			name, policy := "default", defaultPolicy
+			if p, ok := routes[pattern]; ok {
+				name, policy = pattern, p
+			}
+
+			key := name + "|" + rateLimitClient(r, tokens, trusted)
+			res, err := store.Take(r.Context(), key, policy, time.Now())
+			if err != nil {
+				slog.WarnContext(r.Context(), "rate limit store failed, allowing request", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(policy.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", seconds(res.Reset))
+			h.Set("RateLimit-Policy", strconv.Itoa(policy.Burst)+";w="+seconds(policy.Window()))
+
+			if !res.Allowed {
+				// The mux never sees this request; record its route for metrics and logs
+				r.Pattern = pattern
+				h.Set("Retry-After", seconds(res.RetryAfter))
+				problem.Write(w, r, http.StatusTooManyRequests, "Rate limit exceeded, retry after "+seconds(res.RetryAfter)+" seconds")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// rateLimitClient identifies the caller: the user of a valid bearer token, else the client IP
+func rateLimitClient(r *http.Request, tokens *auth.Manager, trusted []*net.IPNet) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		if claims, err := tokens.ValidateToken(token); err == nil {
+			return "user:" + claims.UserID
+		}
+	}
+	return "ip:" + ratelimit.ClientIP(r, trusted)
+}
+
+// seconds rounds d up to whole seconds, as the headers require
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// isAPI reports whether path is served by the JSON API: the /api routes and
+// the GraphQL endpoint
+func isAPI(path string) bool {
+	return strings.HasPrefix(path, "/api/") || path == "/graphql"
+}
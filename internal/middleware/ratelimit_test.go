@@ -0,0 +1,105 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/auth"
+	"github.com/teamserik/online-car-store/internal/config"
+	"github.com/teamserik/online-car-store/internal/middleware"
+	"github.com/teamserik/online-car-store/internal/ratelimit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestRateLimit checks the RateLimit headers, the 429 once a bucket is empty,
+// per-route policies, and that clients get separate buckets
+func TestRateLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	mux.HandleFunc("GET /api/v1/cars", ok)
+	mux.HandleFunc("POST /api/v1/auth/login", ok)
+	mux.HandleFunc("GET /healthz", ok)
+
+	cfg := config.RateLimit{
+		RequestsPerMinute: 60,
+		Burst:             2,
+		Routes:            map[string]string{"POST /api/v1/auth/login": "1/1m"},
+		TrustedProxies:    []string{"10.0.0.0/8"},
+	}
+	tokens := auth.NewManager(config.Default().Auth)
+	limit, err := middleware.RateLimit(cfg, ratelimit.NewMemoryStore(), tokens, mux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := limit(mux)
+
+	send := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "203.0.113.5:4321"
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Headers", func(t *testing.T) {
+		cases := []struct {
+			status     int
+			remaining  string
+			reset      string
+			retryAfter string
+		}{
+			{http.StatusOK, "1", "1", ""},
+			{http.StatusOK, "0", "2", ""},
+			{http.StatusTooManyRequests, "0", "2", "1"},
+		}
+		for i, c := range cases {
+			rec := send(http.MethodGet, "/api/v1/cars", nil)
+			h := rec.Header()
+			if rec.Code != c.status || h.Get("RateLimit-Limit") != "2" || h.Get("RateLimit-Remaining") != c.remaining ||
+				h.Get("RateLimit-Reset") != c.reset || h.Get("RateLimit-Policy") != "2;w=2" || h.Get("Retry-After") != c.retryAfter {
+				t.Errorf("request %d: status %d headers %v, want %d with %s remaining, reset %s and Retry-After %q",
+					i, rec.Code, h, c.status, c.remaining, c.reset, c.retryAfter)
+			}
+		}
+		if ct := send(http.MethodGet, "/api/v1/cars", nil).Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("429 Content-Type %q, want a problem", ct)
+		}
+	})
+
+	t.Run("RoutePolicy", func(t *testing.T) {
+		first := send(http.MethodPost, "/api/v1/auth/login", nil)
+		if first.Code != http.StatusOK || first.Header().Get("RateLimit-Policy") != "1;w=60" {
+			t.Errorf("first login: status %d policy %q, want 200 with the route's 1;w=60", first.Code, first.Header().Get("RateLimit-Policy"))
+		}
+		second := send(http.MethodPost, "/api/v1/auth/login", nil)
+		if second.Code != http.StatusTooManyRequests || second.Header().Get("Retry-After") != "60" {
+			t.Errorf("second login: status %d Retry-After %q, want 429 after 60", second.Code, second.Header().Get("Retry-After"))
+		}
+	})
+
+	t.Run("SeparateClients", func(t *testing.T) {
+		token, err := tokens.GenerateToken(primitive.NewObjectID(), "ivan@example.com", "ivan", "user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec := send(http.MethodGet, "/api/v1/cars", http.Header{"Authorization": {"Bearer " + token}}); rec.Code != http.StatusOK {
+			t.Errorf("signed-in user on an IP that is out of tokens: status %d, want their own bucket", rec.Code)
+		}
+		// The peer is not a trusted proxy, so the header cannot buy a new bucket
+		if rec := send(http.MethodGet, "/api/v1/cars", http.Header{"X-Forwarded-For": {"198.51.100.1"}}); rec.Code != http.StatusTooManyRequests {
+			t.Errorf("spoofed X-Forwarded-For: status %d, want 429", rec.Code)
+		}
+	})
+
+	t.Run("NotLimited", func(t *testing.T) {
+		for _, req := range []struct{ method, path string }{{http.MethodGet, "/healthz"}, {http.MethodOptions, "/api/v1/cars"}} {
+			if rec := send(req.method, req.path, nil); rec.Header().Get("RateLimit-Limit") != "" || rec.Code == http.StatusTooManyRequests {
+				t.Errorf("%s %s: status %d with rate limit headers, want it unlimited", req.method, req.path, rec.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/config"
+)
+
+// apiContentSecurityPolicy locks down JSON responses, which never load anything
+const apiContentSecurityPolicy = "default-src 'none'"
+
+// SecurityHeaders sets browser hardening headers on every response. /api
+// responses get a deny-all CSP; everything else is the static UI and gets
+// cfg.ContentSecurityPolicy. HSTS is only sent over TLS, since browsers ignore
+// it on plain HTTP.
+func SecurityHeaders(cfg config.Security) func(next http.Handler) http.Handler {
+	frameAncestors := "frame-ancestors " + cfg.FrameAncestors
+	apiCSP := apiContentSecurityPolicy + "; " + frameAncestors
+	uiCSP := strings.TrimSuffix(strings.TrimSpace(cfg.ContentSecurityPolicy), ";") + "; " + frameAncestors
+
+	hsts := "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+	if cfg.HSTSIncludeSubdomains {
+		hsts += "; includeSubDomains"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			if isAPI(r.URL.Path) {
+				h.Set("Content-Security-Policy", apiCSP)
+			} else {
+				h.Set("Content-Security-Policy", uiCSP)
+			}
+			if cfg.FrameAncestors == "'none'" {
+				// For browsers that predate frame-ancestors
+				h.Set("X-Frame-Options", "DENY")
+			}
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+			if r.TLS != nil && cfg.HSTSMaxAge > 0 {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
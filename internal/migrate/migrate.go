@@ -0,0 +1,245 @@
+// Package migrate applies versioned schema migrations (mostly indexes) to the
+// MongoDB database and records which ones ran in the schema_migrations collection.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionName is where applied migrations are recorded
+const CollectionName = "schema_migrations"
+
+// LockCollectionName holds the lock that lets one instance migrate at a time
+const LockCollectionName = "schema_migrations_lock"
+
+const (
+	lockID = "lock"
+	// lockTTL frees the lock of an instance that died while migrating
+	lockTTL = 10 * time.Minute
+	// lockPoll is how often a waiting instance tries the lock again
+	lockPoll = time.Second
+)
+
+// Migration is one reversible schema change
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record is the document stored for each applied migration
+type Record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Status describes a migration and whether it has been applied
+type Status struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// Runner applies migrations to a database
+type Runner struct {
+	db         *mongo.Database
+	records    *mongo.Collection
+	locks      *mongo.Collection
+	migrations []Migration
+}
+
+// NewRunner returns a Runner for the given migrations, sorted by version.
+// It panics on duplicate versions, which is a programming error.
+func NewRunner(db *mongo.Database, migrations []Migration) *Runner {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			panic(fmt.Sprintf("migrate: duplicate migration version %d", sorted[i].Version))
+		}
+	}
+
+	return &Runner{
+		db:         db,
+		records:    db.Collection(CollectionName),
+		locks:      db.Collection(LockCollectionName),
+		migrations: sorted,
+	}
+}
+
+// Up applies every pending migration up to and including target; 0 means all.
+// It returns the number of migrations applied. Instances that start together
+// take turns, and the later ones find the migrations already applied.
+func (r *Runner) Up(ctx context.Context, target int) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range r.migrations {
+		if target > 0 && m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		slog.InfoContext(ctx, "applying migration", "version", m.Version, "description", m.Description)
+		if err := m.Up(ctx, r.db); err != nil {
+			return count, fmt.Errorf("migration %d (%s) up: %w", m.Version, m.Description, err)
+		}
+
+		record := Record{Version: m.Version, Description: m.Description, AppliedAt: time.Now()}
+		if _, err := r.records.InsertOne(ctx, record); err != nil {
+			return count, fmt.Errorf("record migration %d: %w", m.Version, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down reverts the most recently applied migrations, steps of them.
+// It returns the number of migrations reverted.
+func (r *Runner) Down(ctx context.Context, steps int) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(r.migrations) - 1; i >= 0 && count < steps; i-- {
+		m := r.migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+
+		slog.InfoContext(ctx, "reverting migration", "version", m.Version, "description", m.Description)
+		if err := m.Down(ctx, r.db); err != nil {
+			return count, fmt.Errorf("migration %d (%s) down: %w", m.Version, m.Description, err)
+		}
+
+		if _, err := r.records.DeleteOne(ctx, bson.M{"_id": m.Version}); err != nil {
+			return count, fmt.Errorf("unrecord migration %d: %w", m.Version, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Status lists every known migration in version order
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		record, ok := applied[m.Version]
+		statuses = append(statuses, Status{
+			Version:     m.Version,
+			Description: m.Description,
+			Applied:     ok,
+			AppliedAt:   record.AppliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// Pending returns how many migrations have not been applied yet
+func (r *Runner) Pending(ctx context.Context) (int, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, m := range r.migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// lock waits until this runner holds the migration lock and returns the
+// function that releases it. A lock older than lockTTL is taken over.
+func (r *Runner) lock(ctx context.Context) (func(), error) {
+	owner := primitive.NewObjectID()
+	for waiting := false; ; waiting = true {
+		now := time.Now()
+		filter := bson.M{"_id": lockID, "expires_at": bson.M{"$lte": now}}
+		update := bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(lockTTL)}}
+		// While another runner holds the lock the filter misses it, and the upsert's insert collides on _id
+		_, err := r.locks.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("take migration lock: %w", err)
+		}
+		if !waiting {
+			slog.InfoContext(ctx, "waiting for another instance to finish migrating")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait for migration lock: %w", ctx.Err())
+		case <-time.After(lockPoll):
+		}
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if _, err := r.locks.DeleteOne(ctx, bson.M{"_id": lockID, "owner": owner}); err != nil {
+			slog.ErrorContext(ctx, "release migration lock", "error", err)
+		}
+	}, nil
+}
+
+func (r *Runner) applied(ctx context.Context) (map[int]Record, error) {
+	cursor, err := r.records.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
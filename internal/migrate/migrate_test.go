@@ -0,0 +1,116 @@
+package migrate_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/migrate"
+	"github.com/teamserik/online-car-store/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TestConcurrentUp starts several runners at once, as instances started with
+// -migrate do, and checks that the migration runs once and every runner
+// succeeds. It is skipped unless TEST_MONGO_URI is set.
+func TestConcurrentUp(t *testing.T) {
+	db := repositorytest.NewMongoDatabase(t)
+
+	var runs atomic.Int32
+	migrations := []migrate.Migration{{
+		Version:     1000,
+		Description: "slow test migration",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			runs.Add(1)
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		},
+		Down: func(context.Context, *mongo.Database) error { return nil },
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = migrate.NewRunner(db, migrations).Up(ctx, 0)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("runner %d: %v", i, err)
+		}
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("the migration ran %d times, want 1", n)
+	}
+}
+
+// TestDuplicates checks the unique index migrations on data saved before
+// them: duplicate favorites are removed, duplicate accounts stop the migration
+// with the shared values. It is skipped unless TEST_MONGO_URI is set.
+func TestDuplicates(t *testing.T) {
+	db := repositorytest.NewMongoDatabase(t)
+	runner := migrate.NewRunner(db, migrate.All)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := runner.Down(ctx, len(migrate.All)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+
+	users, favorites := db.Collection("users"), db.Collection("favorites")
+	userID, carID := primitive.NewObjectID(), primitive.NewObjectID()
+	docs := []struct {
+		collection *mongo.Collection
+		doc        bson.M
+	}{
+		{users, bson.M{"email": "ivan@example.com", "username": "ivan"}},
+		{users, bson.M{"email": "ivan@example.com", "username": "ivan2"}},
+		{favorites, bson.M{"user_id": userID, "car_id": carID, "note": "first"}},
+		{favorites, bson.M{"user_id": userID, "car_id": carID, "note": "second"}},
+	}
+	var ids []any
+	for _, d := range docs {
+		result, err := d.collection.InsertOne(ctx, d.doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, result.InsertedID)
+	}
+
+	_, err := runner.Up(ctx, 0)
+	if err == nil || !strings.Contains(err.Error(), "users.email") || !strings.Contains(err.Error(), "ivan@example.com (2)") {
+		t.Fatalf("Up with duplicate emails = %v, want an error naming ivan@example.com", err)
+	}
+
+	if _, err := users.DeleteOne(ctx, bson.M{"_id": ids[1]}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Up(ctx, 0); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	var kept []bson.M
+	cursor, err := favorites.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cursor.All(ctx, &kept); err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 1 || kept[0]["note"] != "first" {
+		t.Errorf("favorites after Up = %v, want only the oldest", kept)
+	}
+}
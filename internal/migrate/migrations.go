@@ -0,0 +1,317 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// readNotificationTTL is how long read notifications are kept
+const readNotificationTTL = 90 * 24 * time.Hour
+
+// All is the application's migrations. Append new ones with the next version;
+// never renumber or edit a migration that has shipped.
+var All = []Migration{
+	{
+		Version:     1,
+		Description: "unique user email and username",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Duplicate accounts each own data, so someone has to decide which to keep
+			for _, field := range []string{"email", "username"} {
+				if err := requireUnique(ctx, db.Collection("users"), field); err != nil {
+					return err
+				}
+			}
+			return createIndexes(ctx, db.Collection("users"),
+				index("users_email_unique", bson.D{{Key: "email", Value: 1}}, options.Index().SetUnique(true)),
+				index("users_username_unique", bson.D{{Key: "username", Value: 1}}, options.Index().SetUnique(true)),
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("users"), "users_email_unique", "users_username_unique")
+		},
+	},
+	{
+		Version:     2,
+		Description: "car catalog filters, sorting and text search",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("cars"),
+				index("cars_filters", bson.D{
+					{Key: "make", Value: 1},
+					{Key: "body_type", Value: 1},
+					{Key: "fuel_type", Value: 1},
+					{Key: "transmission", Value: 1},
+				}, nil),
+				index("cars_price", bson.D{{Key: "price", Value: 1}}, nil),
+				index("cars_created_at", bson.D{{Key: "created_at", Value: -1}}, nil),
+				index("cars_rating", bson.D{{Key: "rating_avg", Value: -1}, {Key: "rating_count", Value: -1}}, nil),
+				index("cars_dealer", bson.D{{Key: "dealer_id", Value: 1}}, nil),
+				index("cars_text", bson.D{
+					{Key: "make", Value: "text"},
+					{Key: "model", Value: "text"},
+					{Key: "description", Value: "text"},
+				}, options.Index().SetWeights(bson.D{{Key: "make", Value: 5}, {Key: "model", Value: 5}, {Key: "description", Value: 1}})),
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("cars"),
+				"cars_filters", "cars_price", "cars_created_at", "cars_rating", "cars_dealer", "cars_text")
+		},
+	},
+	{
+		Version:     3,
+		Description: "favorites, collections and garage lookups",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Same keys as the auto-named indexes the favorites repository used to create at startup
+			if err := dropIndexes(ctx, db.Collection("favorites"), "user_id_1_car_id_1", "user_id_1_created_at_-1"); err != nil {
+				return err
+			}
+
+			// The old check-then-insert could save the same favorite or garage car twice
+			if err := removeDuplicates(ctx, db.Collection("favorites"), "user_id", "car_id"); err != nil {
+				return err
+			}
+			if err := removeDuplicates(ctx, db.Collection("garage"), "user_id", "car_id"); err != nil {
+				return err
+			}
+
+			err := createIndexes(ctx, db.Collection("favorites"),
+				index("favorites_user_car_unique", bson.D{{Key: "user_id", Value: 1}, {Key: "car_id", Value: 1}}, options.Index().SetUnique(true)),
+				index("favorites_user_created_at", bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}, nil),
+			)
+			if err != nil {
+				return err
+			}
+
+			err = createIndexes(ctx, db.Collection("favorite_collections"),
+				index("favorite_collections_user", bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}, nil),
+				index("favorite_collections_share_token_unique", bson.D{{Key: "share_token", Value: 1}},
+					options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"share_token": bson.M{"$type": "string"}})),
+			)
+			if err != nil {
+				return err
+			}
+
+			return createIndexes(ctx, db.Collection("garage"),
+				index("garage_user_car_unique", bson.D{{Key: "user_id", Value: 1}, {Key: "car_id", Value: 1}}, options.Index().SetUnique(true)),
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db.Collection("favorites"), "favorites_user_car_unique", "favorites_user_created_at"); err != nil {
+				return err
+			}
+			if err := dropIndexes(ctx, db.Collection("favorite_collections"), "favorite_collections_user", "favorite_collections_share_token_unique"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db.Collection("garage"), "garage_user_car_unique")
+		},
+	},
+	{
+		Version:     4,
+		Description: "review listing and one vote per user",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			err := createIndexes(ctx, db.Collection("reviews"),
+				index("reviews_car_created_at", bson.D{{Key: "car_id", Value: 1}, {Key: "created_at", Value: -1}}, nil),
+				index("reviews_car_helpful", bson.D{{Key: "car_id", Value: 1}, {Key: "helpful_score", Value: -1}}, nil),
+				index("reviews_user", bson.D{{Key: "user_id", Value: 1}}, nil),
+			)
+			if err != nil {
+				return err
+			}
+
+			// A review's counts are recomputed from its votes on the next one cast
+			if err := removeDuplicates(ctx, db.Collection("review_votes"), "review_id", "user_id"); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db.Collection("review_votes"),
+				index("review_votes_review_user_unique", bson.D{{Key: "review_id", Value: 1}, {Key: "user_id", Value: 1}}, options.Index().SetUnique(true)),
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db.Collection("reviews"), "reviews_car_created_at", "reviews_car_helpful", "reviews_user"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db.Collection("review_votes"), "review_votes_review_user_unique")
+		},
+	},
+	{
+		Version:     5,
+		Description: "notification inbox and expiry of read notifications",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("notifications"),
+				index("notifications_user_read_created_at", bson.D{
+					{Key: "user_id", Value: 1},
+					{Key: "read", Value: 1},
+					{Key: "created_at", Value: -1},
+				}, nil),
+				index("notifications_read_ttl", bson.D{{Key: "created_at", Value: 1}},
+					options.Index().
+						SetExpireAfterSeconds(int32(readNotificationTTL.Seconds())).
+						SetPartialFilterExpression(bson.M{"read": true})),
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("notifications"), "notifications_user_read_created_at", "notifications_read_ttl")
+		},
+	},
+	{
+		Version:     6,
+		Description: "backfill version on cars and reviews created before optimistic concurrency",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			missing := bson.M{"version": bson.M{"$exists": false}}
+			update := bson.M{"$set": bson.M{"version": 1}}
+
+			if _, err := db.Collection("cars").UpdateMany(ctx, missing, update); err != nil {
+				return err
+			}
+			_, err := db.Collection("reviews").UpdateMany(ctx, missing, update)
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			// Versions written since cannot be told apart from backfilled ones, so keep them
+			return nil
+		},
+	},
+	{
+		Version:     7,
+		Description: "expire rate limit buckets once they have refilled",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("rate_limits"),
+				index("rate_limits_expires_at_ttl", bson.D{{Key: "expires_at", Value: 1}},
+					options.Index().SetExpireAfterSeconds(0)),
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("rate_limits"), "rate_limits_expires_at_ttl")
+		},
+	},
+	{
+		Version:     8,
+		Description: "unique stock number per dealer for inventory sync",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("cars"),
+				index("cars_dealer_stock_number_unique", bson.D{{Key: "dealer_id", Value: 1}, {Key: "stock_number", Value: 1}},
+					options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"stock_number": bson.M{"$type": "string"}})),
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("cars"), "cars_dealer_stock_number_unique")
+		},
+	},
+}
+
+// index builds a named index model; opts may be nil
+func index(name string, keys bson.D, opts *options.IndexOptions) mongo.IndexModel {
+	if opts == nil {
+		opts = options.Index()
+	}
+	return mongo.IndexModel{Keys: keys, Options: opts.SetName(name)}
+}
+
+func createIndexes(ctx context.Context, collection *mongo.Collection, models ...mongo.IndexModel) error {
+	_, err := collection.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+// duplicate is a key that more than one document has, with their IDs oldest first
+type duplicate struct {
+	Key bson.M               `bson:"_id"`
+	IDs []primitive.ObjectID `bson:"ids"`
+}
+
+// findDuplicates returns the values of fields that more than one document shares
+func findDuplicates(ctx context.Context, collection *mongo.Collection, fields ...string) ([]duplicate, error) {
+	key := bson.M{}
+	for _, field := range fields {
+		key[field] = "$" + field
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$group", Value: bson.M{"_id": key, "ids": bson.M{"$push": "$_id"}}}},
+		{{Key: "$match", Value: bson.M{"ids.1": bson.M{"$exists": true}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	var dups []duplicate
+	if err := cursor.All(ctx, &dups); err != nil {
+		return nil, err
+	}
+	return dups, nil
+}
+
+// removeDuplicates keeps the oldest document for each value of fields and
+// deletes the rest, so a unique index on fields can be built
+func removeDuplicates(ctx context.Context, collection *mongo.Collection, fields ...string) error {
+	dups, err := findDuplicates(ctx, collection, fields...)
+	if err != nil {
+		return fmt.Errorf("find duplicate %s: %w", collection.Name(), err)
+	}
+
+	removed := int64(0)
+	for _, dup := range dups {
+		result, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": dup.IDs[1:]}})
+		if err != nil {
+			return fmt.Errorf("remove duplicate %s: %w", collection.Name(), err)
+		}
+		removed += result.DeletedCount
+	}
+	if removed > 0 {
+		slog.InfoContext(ctx, "removed duplicates before adding a unique index", "collection", collection.Name(), "count", removed)
+	}
+	return nil
+}
+
+// maxListedDuplicates caps how many keys requireUnique names in its error
+const maxListedDuplicates = 20
+
+// requireUnique fails, naming the offending values, when documents share a
+// value of field
+func requireUnique(ctx context.Context, collection *mongo.Collection, field string) error {
+	dups, err := findDuplicates(ctx, collection, field)
+	if err != nil {
+		return fmt.Errorf("find duplicate %s: %w", collection.Name(), err)
+	}
+	if len(dups) == 0 {
+		return nil
+	}
+
+	listed := min(len(dups), maxListedDuplicates)
+	values := make([]string, 0, listed+1)
+	for _, dup := range dups[:listed] {
+		values = append(values, fmt.Sprintf("%v (%d)", dup.Key[field], len(dup.IDs)))
+	}
+	if len(dups) > listed {
+		values = append(values, fmt.Sprintf("and %d more", len(dups)-listed))
+	}
+	return fmt.Errorf("%s.%s must be unique, but these values are shared: %s; merge or remove the duplicates, then migrate again",
+		collection.Name(), field, strings.Join(values, ", "))
+}
+
+// dropIndexes drops the named indexes, ignoring ones that are already gone
+func dropIndexes(ctx context.Context, collection *mongo.Collection, names ...string) error {
+	for _, name := range names {
+		if _, err := collection.Indexes().DropOne(ctx, name); err != nil && !isIndexNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == 27 || cmdErr.Code == 26 // IndexNotFound, NamespaceNotFound
+	}
+	return false
+}
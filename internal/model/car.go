@@ -21,41 +21,83 @@ type Car struct {
 	EngineSize   float64            `bson:"engine_size" json:"engine_size"`
 	Description  string             `bson:"description" json:"description"`
 	ImageURL     string             `bson:"image_url" json:"image_url"`
+	DealerID     primitive.ObjectID `bson:"dealer_id,omitempty" json:"dealer_id"`                 // user who listed the car
+	StockNumber  string             `bson:"stock_number,omitempty" json:"stock_number,omitempty"` // dealer's own identifier, unique per dealer
+	RatingAvg    float64            `bson:"rating_avg" json:"rating_avg"`                         // denormalized from reviews
+	RatingCount  int                `bson:"rating_count" json:"rating_count"`                     // denormalized from reviews
+	Version      int64              `bson:"version" json:"version"`                               // incremented on every write; the ETag
 	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 type CreateCarInput struct {
-	Make         string  `json:"make"`
-	Model        string  `json:"model"`
-	Year         int     `json:"year"`
-	Price        float64 `json:"price"`
-	Mileage      int     `json:"mileage"`
-	BodyType     string  `json:"body_type"`
-	FuelType     string  `json:"fuel_type"`
-	Transmission string  `json:"transmission"`
-	Color        string  `json:"color"`
-	HorsePower   int     `json:"horsepower"`
-	EngineSize   float64 `json:"engine_size"`
-	Description  string  `json:"description"`
-	ImageURL     string  `json:"image_url"`
+	Make         string  `json:"make" validate:"required,max=50"`
+	Model        string  `json:"model" validate:"required,max=50"`
+	Year         int     `json:"year" validate:"required,min=1900,notfuture"`
+	Price        float64 `json:"price" validate:"required,min=0"`
+	Mileage      int     `json:"mileage" validate:"min=0,max=2000000"`
+	BodyType     string  `json:"body_type" validate:"required,oneof=Sedan Hatchback SUV Coupe Wagon Crossover Minivan"`
+	FuelType     string  `json:"fuel_type" validate:"required,oneof=Gasoline Diesel Electric Hybrid"`
+	Transmission string  `json:"transmission" validate:"required,oneof=Automatic Manual Robot CVT"`
+	Color        string  `json:"color" validate:"required,max=30"`
+	HorsePower   int     `json:"horsepower" validate:"min=0,max=2000"`
+	EngineSize   float64 `json:"engine_size" validate:"min=0,max=10"`
+	Description  string  `json:"description" validate:"max=5000"`
+	ImageURL     string  `json:"image_url" validate:"omitempty,url,max=2048"`
 }
 
-// Изменено: теперь UpdateCarInput содержит все поля
+// NewCar returns the car the input describes, not yet stored
+func (in CreateCarInput) NewCar() *Car {
+	return &Car{
+		Make:         in.Make,
+		Model:        in.Model,
+		Year:         in.Year,
+		Price:        in.Price,
+		Mileage:      in.Mileage,
+		BodyType:     in.BodyType,
+		FuelType:     in.FuelType,
+		Transmission: in.Transmission,
+		Color:        in.Color,
+		HorsePower:   in.HorsePower,
+		EngineSize:   in.EngineSize,
+		Description:  in.Description,
+		ImageURL:     in.ImageURL,
+	}
+}
+
+// UpdateCarInput for PUT /api/cars/{id}: a full replacement, so every field is written
 type UpdateCarInput struct {
-	Make         string  `json:"make"`
-	Model        string  `json:"model"`
-	Year         int     `json:"year"`
-	Price        float64 `json:"price"`
-	Mileage      int     `json:"mileage"`
-	BodyType     string  `json:"body_type"`
-	FuelType     string  `json:"fuel_type"`
-	Transmission string  `json:"transmission"`
-	Color        string  `json:"color"`
-	HorsePower   int     `json:"horsepower"`
-	EngineSize   float64 `json:"engine_size"`
-	Description  string  `json:"description"`
-	ImageURL     string  `json:"image_url"`
+	Make         string  `json:"make" validate:"required,max=50"`
+	Model        string  `json:"model" validate:"required,max=50"`
+	Year         int     `json:"year" validate:"required,min=1900,notfuture"`
+	Price        float64 `json:"price" validate:"required,min=0"`
+	Mileage      int     `json:"mileage" validate:"min=0,max=2000000"`
+	BodyType     string  `json:"body_type" validate:"required,oneof=Sedan Hatchback SUV Coupe Wagon Crossover Minivan"`
+	FuelType     string  `json:"fuel_type" validate:"required,oneof=Gasoline Diesel Electric Hybrid"`
+	Transmission string  `json:"transmission" validate:"required,oneof=Automatic Manual Robot CVT"`
+	Color        string  `json:"color" validate:"required,max=30"`
+	HorsePower   int     `json:"horsepower" validate:"min=0,max=2000"`
+	EngineSize   float64 `json:"engine_size" validate:"min=0,max=10"`
+	Description  string  `json:"description" validate:"max=5000"`
+	ImageURL     string  `json:"image_url" validate:"omitempty,url,max=2048"`
+}
+
+// PatchCarInput for PATCH /api/cars/{id} (JSON Merge Patch, RFC 7396).
+// Only fields present in the body are changed; null counts as absent, since no car field can be unset.
+type PatchCarInput struct {
+	Make         *string  `json:"make" validate:"required,max=50"`
+	Model        *string  `json:"model" validate:"required,max=50"`
+	Year         *int     `json:"year" validate:"required,min=1900,notfuture"`
+	Price        *float64 `json:"price" validate:"required,min=0"`
+	Mileage      *int     `json:"mileage" validate:"min=0,max=2000000"`
+	BodyType     *string  `json:"body_type" validate:"required,oneof=Sedan Hatchback SUV Coupe Wagon Crossover Minivan"`
+	FuelType     *string  `json:"fuel_type" validate:"required,oneof=Gasoline Diesel Electric Hybrid"`
+	Transmission *string  `json:"transmission" validate:"required,oneof=Automatic Manual Robot CVT"`
+	Color        *string  `json:"color" validate:"required,max=30"`
+	HorsePower   *int     `json:"horsepower" validate:"min=0,max=2000"`
+	EngineSize   *float64 `json:"engine_size" validate:"min=0,max=10"`
+	Description  *string  `json:"description" validate:"max=5000"`
+	ImageURL     *string  `json:"image_url" validate:"omitempty,url,max=2048"`
 }
 
 type FilterParams struct {
@@ -67,4 +109,27 @@ type FilterParams struct {
 	Transmission *string  `json:"transmission"`
 	MinYear      *int     `json:"min_year"`
 	MaxYear      *int     `json:"max_year"`
+	Sort         string   `json:"sort"`
+	// DealerID limits the list to one dealer's cars; set by callers, never from the query
+	DealerID *primitive.ObjectID `json:"-"`
+	// Page and Limit return one page of the list, from page 1; Limit 0 returns every car
+	Page  int `json:"-"`
+	Limit int `json:"-"`
+}
+
+// Car sort options for List
+const (
+	CarSortNewest    = "newest"
+	CarSortPriceAsc  = "price_asc"
+	CarSortPriceDesc = "price_desc"
+	CarSortRating    = "rating"
+)
+
+// ValidCarSort reports whether sort is one of the supported car sort options
+func ValidCarSort(sort string) bool {
+	switch sort {
+	case CarSortNewest, CarSortPriceAsc, CarSortPriceDesc, CarSortRating:
+		return true
+	}
+	return false
 }
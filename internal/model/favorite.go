@@ -8,22 +8,96 @@ import (
 
 // Favorite represents a user's favorite car
 type Favorite struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
-	CarID     primitive.ObjectID `bson:"car_id" json:"car_id"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
+	CarID        primitive.ObjectID `bson:"car_id" json:"car_id"`
+	CollectionID primitive.ObjectID `bson:"collection_id,omitempty" json:"collection_id"` // zero when not in a collection
+	Note         string             `bson:"note,omitempty" json:"note"`                   // private to the owner
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
 }
 
 // FavoriteWithCar represents a favorite with full car details
 type FavoriteWithCar struct {
-	ID        primitive.ObjectID `json:"id"`
-	UserID    primitive.ObjectID `json:"user_id"`
-	CarID     primitive.ObjectID `json:"car_id"`
-	Car       *Car               `json:"car"`
-	CreatedAt time.Time          `json:"created_at"`
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
+	CarID        primitive.ObjectID `bson:"car_id" json:"car_id"`
+	CollectionID primitive.ObjectID `bson:"collection_id,omitempty" json:"collection_id"`
+	Note         string             `bson:"note,omitempty" json:"note"`
+	Car          *Car               `bson:"car" json:"car"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// Favorite sort options for GetUserFavorites
+const (
+	FavoriteSortNewest    = "newest"
+	FavoriteSortOldest    = "oldest"
+	FavoriteSortPriceAsc  = "price_asc"
+	FavoriteSortPriceDesc = "price_desc"
+)
+
+// ValidFavoriteSort reports whether sort is one of the supported favorite sort options
+func ValidFavoriteSort(sort string) bool {
+	switch sort {
+	case FavoriteSortNewest, FavoriteSortOldest, FavoriteSortPriceAsc, FavoriteSortPriceDesc:
+		return true
+	}
+	return false
+}
+
+// FavoriteListParams controls pagination and sorting of favorites
+type FavoriteListParams struct {
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Sort  string `json:"sort"`
+}
+
+// FavoritesResponse is a page of favorites
+type FavoritesResponse struct {
+	Favorites  []FavoriteWithCar `json:"favorites"`
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
+	Sort       string            `json:"sort"`
+}
+
+// FavoriteCollection is a named group of a user's favorites ("Family SUVs", "Weekend car")
+type FavoriteCollection struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name       string             `bson:"name" json:"name"`
+	ShareToken string             `bson:"share_token,omitempty" json:"share_token,omitempty"` // set while the collection is shared
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// SharedCollectionResponse is the read-only view of a shared collection.
+// It leaves out the owner and private notes.
+type SharedCollectionResponse struct {
+	Name      string    `json:"name"`
+	Cars      []Car     `json:"cars"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // AddFavoriteInput for adding a car to favorites
 type AddFavoriteInput struct {
-	CarID string `json:"car_id"`
+	CarID        string `json:"car_id" validate:"required,objectid"`
+	CollectionID string `json:"collection_id,omitempty" validate:"omitempty,objectid"`
+	Note         string `json:"note,omitempty" validate:"max=1000"`
+}
+
+// CollectionInput for creating or renaming a favorite collection
+type CollectionInput struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// MoveFavoriteInput for moving a favorite between collections.
+// An empty CollectionID takes the favorite out of its collection.
+type MoveFavoriteInput struct {
+	CollectionID string `json:"collection_id" validate:"omitempty,objectid"`
+}
+
+// FavoriteNoteInput for setting the private note on a favorite
+type FavoriteNoteInput struct {
+	Note string `json:"note" validate:"max=1000"`
 }
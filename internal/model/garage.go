@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GarageItem is a car a user has saved to their garage
+type GarageItem struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	CarID          primitive.ObjectID `bson:"car_id" json:"car_id"`
+	PriceWhenAdded float64            `bson:"price_when_added" json:"price_when_added"`
+	AddedAt        time.Time          `bson:"added_at" json:"added_at"`
+}
+
+// GarageItemWithCar is a garage item with live car data.
+// Car is nil when the listing has been sold or removed since it was added.
+type GarageItemWithCar struct {
+	ID             primitive.ObjectID `json:"id"`
+	CarID          primitive.ObjectID `json:"car_id"`
+	Car            *Car               `json:"car"`
+	PriceWhenAdded float64            `json:"price_when_added"`
+	PriceChanged   bool               `json:"price_changed"`
+	PriceDelta     float64            `json:"price_delta"` // current price - price when added
+	Sold           bool               `json:"sold"`
+	AddedAt        time.Time          `json:"added_at"`
+}
+
+// AddGarageInput for adding a car to the garage
+type AddGarageInput struct {
+	CarID string `json:"car_id" validate:"required,objectid"`
+}
+
+// ImportGarageInput carries the garage kept in the browser's localStorage:
+// the id and price of each car the client stored.
+type ImportGarageInput struct {
+	Cars []GarageImportCar `json:"cars" validate:"max=500"`
+}
+
+// GarageImportCar is one car from the client's local garage
+type GarageImportCar struct {
+	ID    string  `json:"id"`
+	Price float64 `json:"price"`
+}
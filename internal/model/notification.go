@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification types
+const (
+	NotificationReviewReply = "review_reply"
+)
+
+// Notification is a message shown to a user about activity on their content
+type Notification struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Type      string             `bson:"type" json:"type"`
+	Message   string             `bson:"message" json:"message"`
+	CarID     primitive.ObjectID `bson:"car_id,omitempty" json:"car_id"`
+	ReviewID  primitive.ObjectID `bson:"review_id,omitempty" json:"review_id"`
+	Read      bool               `bson:"read" json:"read"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -8,32 +8,104 @@ import (
 
 // Review represents a car review
 type Review struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	CarID     primitive.ObjectID `bson:"car_id" json:"car_id"`
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CarID          primitive.ObjectID `bson:"car_id" json:"car_id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Username       string             `bson:"username" json:"username"`
+	Rating         int                `bson:"rating" json:"rating"` // 1-5 stars
+	Comment        string             `bson:"comment" json:"comment"`
+	HelpfulCount   int                `bson:"helpful_count" json:"helpful_count"`
+	UnhelpfulCount int                `bson:"unhelpful_count" json:"unhelpful_count"`
+	HelpfulScore   int                `bson:"helpful_score" json:"helpful_score"` // helpful - unhelpful, used by the most_helpful sort
+	Reply          *ReviewReply       `bson:"reply,omitempty" json:"reply,omitempty"`
+	Version        int64              `bson:"version" json:"version"` // incremented on every write; the ETag
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ReviewReply is the single public reply from the listing's dealer or an admin
+type ReviewReply struct {
 	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
 	Username  string             `bson:"username" json:"username"`
-	Rating    int                `bson:"rating" json:"rating"` // 1-5 stars
+	Role      string             `bson:"role" json:"role"`
 	Comment   string             `bson:"comment" json:"comment"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ReviewVote records a user's helpful/unhelpful vote on a review
+type ReviewVote struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ReviewID  primitive.ObjectID `bson:"review_id" json:"review_id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Helpful   bool               `bson:"helpful" json:"helpful"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 }
 
 // CreateReviewInput for adding a review
+// CarID may instead come from the car_id query parameter.
 type CreateReviewInput struct {
-	CarID   string `json:"car_id"`
-	Rating  int    `json:"rating"`
-	Comment string `json:"comment"`
+	CarID   string `json:"car_id" validate:"omitempty,objectid"`
+	Rating  int    `json:"rating" validate:"required,min=1,max=5"`
+	Comment string `json:"comment" validate:"max=2000"`
 }
 
 // UpdateReviewInput for updating a review
 type UpdateReviewInput struct {
-	Rating  int    `json:"rating"`
-	Comment string `json:"comment"`
+	Rating  int    `json:"rating" validate:"required,min=1,max=5"`
+	Comment string `json:"comment" validate:"max=2000"`
+}
+
+// VoteReviewInput for marking a review helpful or unhelpful.
+// Sending the same vote twice removes it.
+type VoteReviewInput struct {
+	Helpful bool `json:"helpful"`
+}
+
+// ReplyReviewInput for replying to a review
+type ReplyReviewInput struct {
+	Comment string `json:"comment" validate:"required,max=2000"`
+}
+
+// Review sort options for GetCarReviews
+const (
+	ReviewSortNewest      = "newest"
+	ReviewSortOldest      = "oldest"
+	ReviewSortHighest     = "highest"
+	ReviewSortLowest      = "lowest"
+	ReviewSortMostHelpful = "most_helpful"
+)
+
+// ReviewListParams controls pagination and sorting of car reviews
+type ReviewListParams struct {
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Sort  string `json:"sort"`
+}
+
+// ValidReviewSort reports whether sort is one of the supported review sort options
+func ValidReviewSort(sort string) bool {
+	switch sort {
+	case ReviewSortNewest, ReviewSortOldest, ReviewSortHighest, ReviewSortLowest, ReviewSortMostHelpful:
+		return true
+	}
+	return false
+}
+
+// RatingStats holds the aggregated rating data for a car
+type RatingStats struct {
+	AverageRating float64     `json:"average_rating"`
+	TotalReviews  int         `json:"total_reviews"`
+	Distribution  map[int]int `json:"rating_distribution"` // stars (1-5) -> number of reviews
 }
 
 // ReviewsResponse with aggregated data
 type ReviewsResponse struct {
-	Reviews       []Review `json:"reviews"`
-	AverageRating float64  `json:"average_rating"`
-	TotalReviews  int      `json:"total_reviews"`
+	Reviews            []Review    `json:"reviews"`
+	AverageRating      float64     `json:"average_rating"`
+	TotalReviews       int         `json:"total_reviews"`
+	RatingDistribution map[int]int `json:"rating_distribution"`
+	Page               int         `json:"page"`
+	Limit              int         `json:"limit"`
+	TotalPages         int         `json:"total_pages"`
+	Sort               string      `json:"sort"`
 }
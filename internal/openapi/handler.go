@@ -0,0 +1,40 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sync"
+)
+
+//go:embed viewer.html
+var viewerHTML string
+
+var viewerTemplate = template.Must(template.New("viewer").Parse(viewerHTML))
+
+// Handler serves the document as JSON. build runs on the first request, so
+// the document can describe routes registered after the handler.
+func Handler(build func() *Document) http.HandlerFunc {
+	encode := sync.OnceValues(func() ([]byte, error) {
+		return json.MarshalIndent(build(), "", "  ")
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := encode()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+// Viewer serves a page that renders the document at specURL
+func Viewer(specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		viewerTemplate.Execute(w, struct{ SpecURL string }{specURL})
+	}
+}
@@ -0,0 +1,274 @@
+// Package openapi builds an OpenAPI 3.1 document for the HTTP API. Routes are
+// described with Endpoint; request and response schemas are generated from
+// the Go types they are decoded into and encoded from, following their json
+// and validate tags.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/problem"
+)
+
+// Version is the OpenAPI version of the documents built here
+const Version = "3.1.0"
+
+// BearerAuth names the security scheme for JWT bearer tokens
+const BearerAuth = "bearerAuth"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations on one path, keyed by lower-case method
+type PathItem map[string]*Operation
+
+// Operation is one method on one path
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the body an operation accepts
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one possible response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header is a response header
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType is the schema of a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how clients authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Param describes a query or header parameter of an Endpoint
+type Param struct {
+	Name        string
+	Description string
+	// Type is a JSON Schema type; string when empty
+	Type     string
+	Enum     []string
+	Required bool
+}
+
+// Endpoint describes a route for the document
+type Endpoint struct {
+	Summary     string
+	Description string
+	Tag         string
+	// Auth is set when the route requires a bearer token
+	Auth    bool
+	Query   []Param
+	Headers []Param
+	// Request is a value of the type the JSON body is decoded into; nil when there is no body
+	Request any
+	// Status is the success status, 200 when zero
+	Status int
+	// Response is a value of the type the JSON body is encoded from; nil when there is no JSON body
+	Response any
+	// ContentType is the media type of a success response that is not JSON
+	ContentType string
+	// Errors are the statuses of the problem responses besides the ones
+	// every endpoint can give
+	Errors     []int
+	Deprecated bool
+}
+
+// Builder assembles a Document from endpoints
+type Builder struct {
+	doc     *Document
+	schemas *schemas
+}
+
+// New returns a builder for a document with info
+func New(info Info) *Builder {
+	s := newSchemas()
+	return &Builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]PathItem{},
+			Components: Components{
+				Schemas: s.components,
+				SecuritySchemes: map[string]SecurityScheme{
+					BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Token from POST /api/v1/auth/login or /register"},
+				},
+			},
+		},
+		schemas: s,
+	}
+}
+
+// Tag adds a tag with a description; operations may use tags that were not added
+func (b *Builder) Tag(name, description string) {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+}
+
+// wildcard matches a ServeMux wildcard such as {id} or {path...}
+var wildcard = regexp.MustCompile(`\{([^}.$]+)(\.\.\.)?\}`)
+
+// Add documents e for method and path, a ServeMux pattern path such as /api/v1/cars/{id}
+func (b *Builder) Add(method, path string, e Endpoint) {
+	op := &Operation{
+		OperationID: operationID(method, path),
+		Summary:     e.Summary,
+		Description: e.Description,
+		Responses:   map[string]Response{},
+		Deprecated:  e.Deprecated,
+	}
+	if e.Tag != "" {
+		op.Tags = []string{e.Tag}
+	}
+
+	for _, m := range wildcard.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	for _, p := range e.Query {
+		op.Parameters = append(op.Parameters, p.parameter("query"))
+	}
+	for _, p := range e.Headers {
+		op.Parameters = append(op.Parameters, p.parameter("header"))
+	}
+
+	if e.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: b.schemas.request(e.Request)}},
+		}
+	}
+
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	switch {
+	case e.Response != nil:
+		success.Content = map[string]MediaType{"application/json": {Schema: b.schemas.response(e.Response)}}
+	case e.ContentType != "":
+		success.Content = map[string]MediaType{e.ContentType: {Schema: &Schema{Type: "string"}}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errors := e.Errors
+	if e.Request != nil {
+		errors = append(errors, http.StatusBadRequest)
+	}
+	if e.Auth {
+		op.Security = []map[string][]string{{BearerAuth: {}}}
+		errors = append(errors, http.StatusUnauthorized)
+	}
+	problems := map[string]MediaType{"application/problem+json": {Schema: b.schemas.response(problem.Problem{})}}
+	for _, code := range errors {
+		op.Responses[strconv.Itoa(code)] = Response{Description: http.StatusText(code), Content: problems}
+	}
+	op.Responses["default"] = Response{Description: "Unexpected error", Content: problems}
+
+	path = wildcard.ReplaceAllString(path, "{$1}")
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// Document returns the document built so far, with tags sorted by name
+func (b *Builder) Document() *Document {
+	sort.Slice(b.doc.Tags, func(i, j int) bool { return b.doc.Tags[i].Name < b.doc.Tags[j].Name })
+	return b.doc
+}
+
+func (p Param) parameter(in string) Parameter {
+	schema := &Schema{Type: p.Type}
+	if p.Type == "" {
+		schema.Type = "string"
+	}
+	for _, v := range p.Enum {
+		schema.Enum = append(schema.Enum, v)
+	}
+	return Parameter{Name: p.Name, In: in, Description: p.Description, Required: p.Required, Schema: schema}
+}
+
+// operationID derives a stable id like getApiV1CarsById from method and path
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if m := wildcard.FindStringSubmatch(segment); m != nil {
+			b.WriteString("By")
+			segment = m[1]
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '.' || r == '-' || r == '_' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
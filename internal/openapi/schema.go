@@ -0,0 +1,206 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schema is a JSON Schema (draft 2020-12, as used by OpenAPI 3.1)
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"` // a type name, or a list of them
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // a *Schema, or true
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// objectIDPattern is the hex form of a MongoDB ObjectID
+const objectIDPattern = "^[0-9a-f]{24}$"
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+// schemas generates schemas for Go types, keeping named structs as components
+type schemas struct {
+	components map[string]*Schema
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}}
+}
+
+// request is the schema of a body decoded into v's type: required fields are
+// the ones validated as required, and validate rules become constraints
+func (s *schemas) request(v any) *Schema {
+	return s.of(reflect.TypeOf(v), true)
+}
+
+// response is the schema of a body encoded from v's type: every field without
+// omitempty is always present
+func (s *schemas) response(v any) *Schema {
+	return s.of(reflect.TypeOf(v), false)
+}
+
+func (s *schemas) of(t reflect.Type, input bool) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string", Pattern: objectIDPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(s.of(t.Elem(), input))
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.of(t.Elem(), input)}
+	case reflect.Map:
+		schema := &Schema{Type: "object", AdditionalProperties: s.of(t.Elem(), input)}
+		if t.Key().Kind() != reflect.String {
+			schema.PropertyNames = &Schema{Pattern: "^-?[0-9]+$"}
+		}
+		return schema
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t, input)
+		}
+		ref := &Schema{Ref: "#/components/schemas/" + t.Name()}
+		if _, ok := s.components[t.Name()]; !ok {
+			// Reserve the name first so recursive types refer to themselves
+			s.components[t.Name()] = &Schema{}
+			*s.components[t.Name()] = *s.object(t, input)
+		}
+		return ref
+	}
+	panic("openapi: no schema for " + t.String())
+}
+
+// object is the schema of a struct's JSON fields
+func (s *schemas) object(t reflect.Type, input bool) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := s.of(field.Type, input)
+		rules := field.Tag.Get("validate")
+		if input {
+			applyRules(prop, field.Type, rules)
+		}
+		schema.Properties[name] = prop
+
+		required := !strings.Contains(opts, "omitempty")
+		if input {
+			required = field.Type.Kind() != reflect.Pointer && hasRule(rules, "required")
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// applyRules turns validate rules into schema constraints
+func applyRules(schema *Schema, t reflect.Type, rules string) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if len(schema.AnyOf) > 0 {
+			schema = schema.AnyOf[0]
+		}
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			bound := int(n)
+			switch {
+			case t.Kind() == reflect.String && name == "min":
+				schema.MinLength = &bound
+			case t.Kind() == reflect.String:
+				schema.MaxLength = &bound
+			case t.Kind() == reflect.Slice && name == "min":
+				schema.MinItems = &bound
+			case t.Kind() == reflect.Slice:
+				schema.MaxItems = &bound
+			case name == "min":
+				schema.Minimum = &n
+			default:
+				schema.Maximum = &n
+			}
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "objectid":
+			schema.Pattern = objectIDPattern
+		case "notfuture":
+			schema.Description = "Not later than the current year"
+		}
+	}
+}
+
+func hasRule(rules, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable allows null besides schema
+func nullable(schema *Schema) *Schema {
+	if t, ok := schema.Type.(string); ok && schema.Ref == "" {
+		schema.Type = []string{t, "null"}
+		return schema
+	}
+	return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+}
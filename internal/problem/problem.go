@@ -0,0 +1,110 @@
+// Package problem writes error responses as RFC 7807 problem details
+// (application/problem+json) so every endpoint fails the same way.
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/logging"
+)
+
+// ContentType is the media type of problem responses
+const ContentType = "application/problem+json"
+
+// StatusClientClosedRequest is used when the client went away before the
+// response was ready; nobody reads it, but logs and metrics do
+const StatusClientClosedRequest = 499
+
+// Problem is an RFC 7807 problem details object
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Errors   []domain.FieldError `json:"errors,omitempty"` // invalid fields for validation problems
+	// RequestID lets clients quote the failing request when reporting a problem
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Write sends a problem response with the given status and detail message
+func Write(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	send(w, &Problem{
+		Type:      "about:blank",
+		Title:     statusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: logging.RequestID(r.Context()),
+	})
+}
+
+// Error sends err as a problem response. Domain errors are mapped to their status
+// code and message; anything else is logged and reported as a 500 without details.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	status := StatusFor(err)
+
+	p := &Problem{
+		Type:      "about:blank",
+		Title:     statusText(status),
+		Status:    status,
+		Detail:    err.Error(),
+		Instance:  r.URL.Path,
+		RequestID: logging.RequestID(r.Context()),
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		p.Detail = "One or more fields are invalid"
+		p.Errors = validationErr.Fields
+	}
+
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+		p.Detail = "An unexpected error occurred"
+	}
+
+	send(w, p)
+}
+
+// StatusFor returns the HTTP status code for err
+func StatusFor(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidID), errors.Is(err, domain.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func statusText(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+func send(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,84 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/ratelimit"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	cases := []struct {
+		specs []string
+		want  []string
+		ok    bool
+	}{
+		{nil, []string{}, true},
+		{[]string{"10.0.0.0/8", "192.168.1.7", "fd00::/8", "::1"}, []string{"10.0.0.0/8", "192.168.1.7/32", "fd00::/8", "::1/128"}, true},
+		{[]string{"10.0.0.0/33"}, nil, false},
+		{[]string{"proxy.internal"}, nil, false},
+	}
+	for _, c := range cases {
+		nets, err := ratelimit.ParseTrustedProxies(c.specs)
+		if (err == nil) != c.ok {
+			t.Errorf("ParseTrustedProxies(%q) error %v, want ok %v", c.specs, err, c.ok)
+			continue
+		}
+		if !c.ok {
+			continue
+		}
+		got := make([]string, len(nets))
+		for i, n := range nets {
+			got[i] = n.String()
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("ParseTrustedProxies(%q) = %q, want %q", c.specs, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("ParseTrustedProxies(%q) = %q, want %q", c.specs, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ratelimit.ParseTrustedProxies([]string{"10.0.0.0/8", "2001:db8::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		remote string
+		xff    []string
+		want   string
+	}{
+		{"Direct", "203.0.113.5:4321", nil, "203.0.113.5"},
+		{"UntrustedPeerIgnoresHeader", "203.0.113.5:4321", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"TrustedPeer", "10.0.0.2:80", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"RightmostUntrustedHop", "10.0.0.2:80", []string{"1.1.1.1, 198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"SpoofedLeftEntries", "10.0.0.2:80", []string{"127.0.0.1, 198.51.100.1"}, "198.51.100.1"},
+		{"SeveralHeaders", "10.0.0.2:80", []string{"198.51.100.1", "10.0.0.9"}, "198.51.100.1"},
+		{"AllHopsTrusted", "10.0.0.2:80", []string{"10.1.1.1, 10.0.0.3"}, "10.1.1.1"},
+		{"NoHeaderFromProxy", "10.0.0.2:80", nil, "10.0.0.2"},
+		{"MalformedHopStops", "10.0.0.2:80", []string{"198.51.100.1, garbage, 10.0.0.3"}, "10.0.0.3"},
+		{"IPv6Proxy", "[2001:db8::1]:443", []string{"2001:db8::99"}, "2001:db8::99"},
+		{"NoPort", "203.0.113.5", nil, "203.0.113.5"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/cars", nil)
+			r.RemoteAddr = c.remote
+			for _, v := range c.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := ratelimit.ClientIP(r, trusted); got != c.want {
+				t.Errorf("ClientIP = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
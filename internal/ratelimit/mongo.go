@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore keeps buckets in a collection so all instances share them.
+// Each take is a single atomic upsert; idle buckets expire through the TTL
+// index on expires_at created by the migrations.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+var _ Store = (*MongoStore)(nil)
+
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+type bucketDocument struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+func (s *MongoStore) Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	burst := float64(policy.Burst)
+	// Subtracting dates gives milliseconds; intervals can be shorter than one
+	perMilli := float64(time.Millisecond) / float64(policy.Interval)
+
+	// The same refill-then-take as MemoryStore, evaluated by the server
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{burst, bson.M{"$add": bson.A{
+				bson.M{"$ifNull": bson.A{"$tokens", burst}},
+				bson.M{"$multiply": bson.A{
+					bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updated_at", now}}}}}},
+					perMilli,
+				}},
+			}}}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": bson.M{"$gte": bson.A{"$tokens", 1}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"tokens":     bson.M{"$cond": bson.A{"$allowed", bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+			"updated_at": now,
+			"expires_at": now.Add(policy.Window()),
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var doc bucketDocument
+	if err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&doc); err != nil {
+		return Result{}, err
+	}
+	return result(policy, doc.Tokens, doc.Allowed), nil
+}
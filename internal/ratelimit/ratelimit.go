@@ -0,0 +1,84 @@
+// Package ratelimit implements token-bucket rate limiting. Buckets live in a
+// Store: MemoryStore for a single instance, MongoStore to share limits
+// between instances.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy is a token bucket that holds Burst tokens and gains one every Interval
+type Policy struct {
+	Burst    int
+	Interval time.Duration
+}
+
+// PerMinute is a policy allowing requests per minute with the given burst
+func PerMinute(requests, burst int) Policy {
+	return Policy{Burst: burst, Interval: time.Minute / time.Duration(requests)}
+}
+
+// ParsePolicy parses "N/period", e.g. "10/1m": bursts of 10 refilled over a minute
+func ParsePolicy(spec string) (Policy, error) {
+	count, period, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("rate limit %q is not N/period", spec)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 {
+		return Policy{}, fmt.Errorf("rate limit %q: request count must be a positive integer", spec)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Policy{}, fmt.Errorf("rate limit %q: period must be a positive duration", spec)
+	}
+	return Policy{Burst: n, Interval: d / time.Duration(n)}, nil
+}
+
+// Window is how long an empty bucket takes to fill up again
+func (p Policy) Window() time.Duration {
+	return p.Interval * time.Duration(p.Burst)
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left
+	Remaining int
+	// RetryAfter is how long until the next token, when not allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps one bucket per key
+type Store interface {
+	// Take refills the bucket for key according to policy and removes a token if one is left
+	Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error)
+}
+
+// refill returns the tokens in a bucket that held tokens at last, now
+func refill(policy Policy, tokens float64, last, now time.Time) float64 {
+	if elapsed := now.Sub(last); elapsed > 0 {
+		tokens += float64(elapsed) / float64(policy.Interval)
+	}
+	return math.Min(tokens, float64(policy.Burst))
+}
+
+// result describes a bucket after a take attempt
+func result(policy Policy, tokens float64, allowed bool) Result {
+	r := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(policy.Burst) - tokens) * float64(policy.Interval)),
+	}
+	if !allowed {
+		r.RetryAfter = time.Duration((1 - tokens) * float64(policy.Interval))
+	}
+	return r
+}
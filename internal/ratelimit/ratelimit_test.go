@@ -0,0 +1,123 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/ratelimit"
+	"github.com/teamserik/online-car-store/internal/repository/repositorytest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) ratelimit.Store { return ratelimit.NewMemoryStore() })
+}
+
+// TestMongoStore is skipped unless TEST_MONGO_URI is set
+func TestMongoStore(t *testing.T) {
+	testStore(t, func(t *testing.T) ratelimit.Store {
+		return ratelimit.NewMongoStore(repositorytest.NewMongoDatabase(t).Collection("rate_limits"))
+	})
+}
+
+// testStore takes tokens from a bucket at the given offsets from a start time
+// and checks each result. Times are whole milliseconds, as MongoDB stores them.
+func testStore(t *testing.T, newStore func(t *testing.T) ratelimit.Store) {
+	type take struct {
+		at         time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+		reset      time.Duration
+	}
+
+	cases := []struct {
+		name   string
+		policy ratelimit.Policy
+		takes  []take
+	}{
+		{"Burst", ratelimit.Policy{Burst: 3, Interval: time.Second}, []take{
+			{0, true, 2, 0, time.Second},
+			{0, true, 1, 0, 2 * time.Second},
+			{0, true, 0, 0, 3 * time.Second},
+			{0, false, 0, time.Second, 3 * time.Second},
+		}},
+		{"Refill", ratelimit.Policy{Burst: 2, Interval: time.Second}, []take{
+			{0, true, 1, 0, time.Second},
+			{0, true, 0, 0, 2 * time.Second},
+			{400 * time.Millisecond, false, 0, 600 * time.Millisecond, 1600 * time.Millisecond},
+			{1500 * time.Millisecond, true, 0, 0, 1500 * time.Millisecond},
+			{time.Hour, true, 1, 0, time.Second},
+		}},
+		{"PerMinute", ratelimit.PerMinute(60, 1), []take{
+			{0, true, 0, 0, time.Second},
+			{250 * time.Millisecond, false, 0, 750 * time.Millisecond, 750 * time.Millisecond},
+			{time.Second, true, 0, 0, time.Second},
+		}},
+		// Shorter than MongoDB's millisecond dates
+		{"SubMillisecondInterval", ratelimit.Policy{Burst: 2, Interval: 100 * time.Microsecond}, []take{
+			{0, true, 1, 0, 100 * time.Microsecond},
+			{0, true, 0, 0, 200 * time.Microsecond},
+			{0, false, 0, 100 * time.Microsecond, 200 * time.Microsecond},
+			{time.Millisecond, true, 1, 0, 100 * time.Microsecond},
+		}},
+	}
+
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			store := newStore(t)
+			for i, take := range c.takes {
+				got, err := store.Take(context.Background(), c.name, c.policy, start.Add(take.at))
+				if err != nil {
+					t.Fatalf("take %d: %v", i, err)
+				}
+				if got.Allowed != take.allowed || got.Remaining != take.remaining ||
+					!near(got.RetryAfter, take.retryAfter) || !near(got.Reset, take.reset) {
+					t.Errorf("take %d at %v = %+v, want allowed %v, %d remaining, retry after %v, reset %v",
+						i, take.at, got, take.allowed, take.remaining, take.retryAfter, take.reset)
+				}
+			}
+		})
+	}
+
+	t.Run("KeysAreIndependent", func(t *testing.T) {
+		store := newStore(t)
+		policy := ratelimit.Policy{Burst: 1, Interval: time.Minute}
+		for _, key := range []string{"a", "b"} {
+			if got, err := store.Take(context.Background(), key, policy, start); err != nil || !got.Allowed {
+				t.Errorf("first take for %s = %+v, %v, want allowed", key, got, err)
+			}
+		}
+	})
+}
+
+// near reports whether two durations differ by less than a microsecond of
+// floating point error
+func near(a, b time.Duration) bool {
+	d := a - b
+	return d > -time.Microsecond && d < time.Microsecond
+}
+
+func TestParsePolicy(t *testing.T) {
+	cases := []struct {
+		spec string
+		want ratelimit.Policy
+		ok   bool
+	}{
+		{"10/1m", ratelimit.Policy{Burst: 10, Interval: 6 * time.Second}, true},
+		{" 1000/1s ", ratelimit.Policy{Burst: 1000, Interval: time.Millisecond}, true},
+		{"3/1ms", ratelimit.Policy{Burst: 3, Interval: time.Millisecond / 3}, true},
+		{"10", ratelimit.Policy{}, false},
+		{"0/1m", ratelimit.Policy{}, false},
+		{"ten/1m", ratelimit.Policy{}, false},
+		{"10/0s", ratelimit.Policy{}, false},
+		{"10/minute", ratelimit.Policy{}, false},
+	}
+	for _, c := range cases {
+		got, err := ratelimit.ParsePolicy(c.spec)
+		if (err == nil) != c.ok || got != c.want {
+			t.Errorf("ParsePolicy(%q) = %+v, %v; want %+v, ok %v", c.spec, got, err, c.want, c.ok)
+		}
+	}
+}
@@ -8,16 +8,31 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type CarRepository interface {
+	// Create fails with domain.ErrConflict when the dealer already has a car with car.StockNumber
 	Create(ctx context.Context, car *model.Car) error
 	GetByID(ctx context.Context, id string) (*model.Car, error)
+	// GetByIDs returns the cars that exist among ids, in no particular order
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.Car, error)
 	List(ctx context.Context, filter *model.FilterParams) ([]*model.Car, error)
-	Update(ctx context.Context, id string, input model.UpdateCarInput) error
-	Delete(ctx context.Context, id string) error
+	// Count returns how many cars match filter, ignoring its Page and Limit
+	Count(ctx context.Context, filter *model.FilterParams) (int64, error)
+	// Update, Patch and Delete take the version the caller last saw and fail with
+	// domain.ErrPreconditionFailed if the car has changed since; AnyVersion skips the check.
+	Update(ctx context.Context, id string, input model.UpdateCarInput, version int64) (*model.Car, error)
+	Patch(ctx context.Context, id string, input model.PatchCarInput, version int64) (*model.Car, error)
+	Delete(ctx context.Context, id string, version int64) error
+	// UpsertByStockNumber replaces the listing fields of the dealer's car with
+	// stockNumber, or lists a new car when the dealer has none. created reports which.
+	UpsertByStockNumber(ctx context.Context, dealerID primitive.ObjectID, stockNumber string, input model.UpdateCarInput) (car *model.Car, created bool, err error)
 }
 
+// AnyVersion disables the optimistic concurrency check on writes
+const AnyVersion int64 = 0
+
 type mongoCarRepository struct {
 	collection *mongo.Collection
 }
@@ -30,15 +45,16 @@ func NewMongoCarRepository(collection *mongo.Collection) CarRepository {
 
 func (r *mongoCarRepository) Create(ctx context.Context, car *model.Car) error {
 	car.ID = primitive.NewObjectID()
+	car.Version = 1
 	car.CreatedAt = time.Now()
 	car.UpdatedAt = time.Now()
 
 	_, err := r.collection.InsertOne(ctx, car)
-	return err
+	return mongoError(err, "car with this stock number")
 }
 
 func (r *mongoCarRepository) GetByID(ctx context.Context, id string) (*model.Car, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := parseObjectID(id, "car")
 	if err != nil {
 		return nil, err
 	}
@@ -46,13 +62,61 @@ func (r *mongoCarRepository) GetByID(ctx context.Context, id string) (*model.Car
 	var car model.Car
 	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&car)
 	if err != nil {
-		return nil, err
+		return nil, mongoError(err, "car")
 	}
 
 	return &car, nil
 }
 
+func (r *mongoCarRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.Car, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	cars := []*model.Car{}
+	if err = cursor.All(ctx, &cars); err != nil {
+		return nil, err
+	}
+
+	return cars, nil
+}
+
 func (r *mongoCarRepository) List(ctx context.Context, filter *model.FilterParams) ([]*model.Car, error) {
+	opts := options.Find()
+	if filter != nil {
+		sort := carSortOrder(filter.Sort)
+		if filter.Limit > 0 {
+			// Pages need a total order, or ties could show up on two pages
+			sort = append(sort, bson.E{Key: "_id", Value: 1})
+			opts.SetSkip(int64((max(filter.Page, 1) - 1) * filter.Limit)).SetLimit(int64(filter.Limit))
+		}
+		if sort != nil {
+			opts.SetSort(sort)
+		}
+	}
+
+	cursor, err := r.collection.Find(ctx, carQuery(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var cars []*model.Car
+	if err = cursor.All(ctx, &cars); err != nil {
+		return nil, err
+	}
+
+	return cars, nil
+}
+
+func (r *mongoCarRepository) Count(ctx context.Context, filter *model.FilterParams) (int64, error) {
+	return r.collection.CountDocuments(ctx, carQuery(filter))
+}
+
+// carQuery is the Mongo filter matching the cars selected by filter
+func carQuery(filter *model.FilterParams) bson.M {
 	query := bson.M{}
 
 	if filter != nil {
@@ -83,6 +147,10 @@ func (r *mongoCarRepository) List(ctx context.Context, filter *model.FilterParam
 			query["transmission"] = *filter.Transmission
 		}
 
+		if filter.DealerID != nil {
+			query["dealer_id"] = *filter.DealerID
+		}
+
 		if filter.MinYear != nil || filter.MaxYear != nil {
 			yearFilter := bson.M{}
 			if filter.MinYear != nil {
@@ -95,56 +163,145 @@ func (r *mongoCarRepository) List(ctx context.Context, filter *model.FilterParam
 		}
 	}
 
-	cursor, err := r.collection.Find(ctx, query)
+	return query
+}
+
+// carSortOrder maps a catalog sort option to a Mongo sort document
+func carSortOrder(sort string) bson.D {
+	switch sort {
+	case model.CarSortNewest:
+		return bson.D{{Key: "created_at", Value: -1}}
+	case model.CarSortPriceAsc:
+		return bson.D{{Key: "price", Value: 1}}
+	case model.CarSortPriceDesc:
+		return bson.D{{Key: "price", Value: -1}}
+	case model.CarSortRating:
+		return bson.D{{Key: "rating_avg", Value: -1}, {Key: "rating_count", Value: -1}}
+	}
+	return nil
+}
+
+// Изменено: теперь обновляет все поля
+func (r *mongoCarRepository) Update(ctx context.Context, id string, input model.UpdateCarInput, version int64) (*model.Car, error) {
+	return r.set(ctx, id, version, listingFields(input))
+}
+
+// listingFields are the fields a full update replaces
+func listingFields(input model.UpdateCarInput) bson.M {
+	return bson.M{
+		"make":         input.Make,
+		"model":        input.Model,
+		"year":         input.Year,
+		"price":        input.Price,
+		"mileage":      input.Mileage,
+		"body_type":    input.BodyType,
+		"fuel_type":    input.FuelType,
+		"transmission": input.Transmission,
+		"color":        input.Color,
+		"horsepower":   input.HorsePower,
+		"engine_size":  input.EngineSize,
+		"description":  input.Description,
+		"image_url":    input.ImageURL,
+	}
+}
+
+// Patch changes only the fields present in input
+func (r *mongoCarRepository) Patch(ctx context.Context, id string, input model.PatchCarInput, version int64) (*model.Car, error) {
+	fields := bson.M{}
+	setIf(fields, "make", input.Make)
+	setIf(fields, "model", input.Model)
+	setIf(fields, "year", input.Year)
+	setIf(fields, "price", input.Price)
+	setIf(fields, "mileage", input.Mileage)
+	setIf(fields, "body_type", input.BodyType)
+	setIf(fields, "fuel_type", input.FuelType)
+	setIf(fields, "transmission", input.Transmission)
+	setIf(fields, "color", input.Color)
+	setIf(fields, "horsepower", input.HorsePower)
+	setIf(fields, "engine_size", input.EngineSize)
+	setIf(fields, "description", input.Description)
+	setIf(fields, "image_url", input.ImageURL)
+
+	return r.set(ctx, id, version, fields)
+}
+
+// set applies fields with $set, bumps updated_at and the version and returns the updated car
+func (r *mongoCarRepository) set(ctx context.Context, id string, version int64, fields bson.M) (*model.Car, error) {
+	objectID, err := parseObjectID(id, "car")
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	var cars []*model.Car
-	if err = cursor.All(ctx, &cars); err != nil {
+	fields["updated_at"] = time.Now()
+	update := bson.M{
+		"$set": fields,
+		"$inc": bson.M{"version": 1},
+	}
+
+	var car model.Car
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = r.collection.FindOneAndUpdate(ctx, versionFilter(objectID, version), update, opts).Decode(&car)
+	if err == mongo.ErrNoDocuments {
+		return nil, versionMismatch(ctx, r.collection, objectID, version, "car")
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	return cars, nil
+	return &car, nil
 }
 
-// Изменено: теперь обновляет все поля
-func (r *mongoCarRepository) Update(ctx context.Context, id string, input model.UpdateCarInput) error {
-	objectID, err := primitive.ObjectIDFromHex(id)
+// setIf adds key to fields when the patch value was provided
+func setIf[T any](fields bson.M, key string, value *T) {
+	if value != nil {
+		fields[key] = *value
+	}
+}
+
+func (r *mongoCarRepository) Delete(ctx context.Context, id string, version int64) error {
+	objectID, err := parseObjectID(id, "car")
 	if err != nil {
 		return err
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"make":         input.Make,
-			"model":        input.Model,
-			"year":         input.Year,
-			"price":        input.Price,
-			"mileage":      input.Mileage,
-			"body_type":    input.BodyType,
-			"fuel_type":    input.FuelType,
-			"transmission": input.Transmission,
-			"color":        input.Color,
-			"horsepower":   input.HorsePower,
-			"engine_size":  input.EngineSize,
-			"description":  input.Description,
-			"image_url":    input.ImageURL,
-			"updated_at":   time.Now(),
-		},
+	result, err := r.collection.DeleteOne(ctx, versionFilter(objectID, version))
+	if err != nil {
+		return err
 	}
 
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	return err
+	if result.DeletedCount == 0 {
+		return versionMismatch(ctx, r.collection, objectID, version, "car")
+	}
+
+	return nil
 }
 
-func (r *mongoCarRepository) Delete(ctx context.Context, id string) error {
-	objectID, err := primitive.ObjectIDFromHex(id)
+// UpsertByStockNumber relies on the unique dealer_id and stock_number index:
+// concurrent upserts of the same new stock number are retried by the server
+// instead of creating two cars
+func (r *mongoCarRepository) UpsertByStockNumber(ctx context.Context, dealerID primitive.ObjectID, stockNumber string, input model.UpdateCarInput) (*model.Car, bool, error) {
+	now := time.Now()
+	id := primitive.NewObjectID()
+
+	fields := listingFields(input)
+	fields["updated_at"] = now
+	update := bson.M{
+		"$set": fields,
+		"$inc": bson.M{"version": 1},
+		"$setOnInsert": bson.M{
+			"_id":          id,
+			"rating_avg":   0,
+			"rating_count": 0,
+			"created_at":   now,
+		},
+	}
+
+	var car model.Car
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"dealer_id": dealerID, "stock_number": stockNumber}, update, opts).Decode(&car)
 	if err != nil {
-		return err
+		return nil, false, mongoError(err, "car")
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
-	return err
+	return &car, car.ID == id, nil
 }
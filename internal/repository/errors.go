@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoError maps driver errors to domain errors for the named resource
+func mongoError(err error, resource string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return domain.NotFound(resource)
+	case mongo.IsDuplicateKeyError(err):
+		return domain.Conflict(resource + " already exists")
+	}
+	return err
+}
+
+// versionFilter matches the document with the given ID, and the given version unless it is AnyVersion
+func versionFilter(id primitive.ObjectID, version int64) bson.M {
+	filter := bson.M{"_id": id}
+	if version != AnyVersion {
+		filter["version"] = version
+	}
+	return filter
+}
+
+// versionMismatch explains why a versioned write matched nothing:
+// the document is gone, or it exists with a different version
+func versionMismatch(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, version int64, resource string) error {
+	if version == AnyVersion {
+		return domain.NotFound(resource)
+	}
+
+	count, err := collection.CountDocuments(ctx, bson.M{"_id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.NotFound(resource)
+	}
+	return domain.PreconditionFailed(resource)
+}
+
+// parseObjectID parses a hex ID, returning domain.ErrInvalidID for malformed input
+func parseObjectID(id, resource string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, domain.InvalidID(resource)
+	}
+	return objectID, nil
+}
@@ -2,53 +2,85 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/teamserik/online-car-store/internal/domain"
 	"github.com/teamserik/online-car-store/internal/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type FavoriteRepository interface {
-	AddToFavorites(ctx context.Context, userID, carID primitive.ObjectID) error
+	AddToFavorites(ctx context.Context, favorite *model.Favorite) error
 	RemoveFromFavorites(ctx context.Context, userID, carID primitive.ObjectID) error
-	GetUserFavorites(ctx context.Context, userID primitive.ObjectID) ([]model.FavoriteWithCar, error)
+	GetUserFavorites(ctx context.Context, userID primitive.ObjectID, params model.FavoriteListParams) (*model.FavoritesResponse, error)
 	GetFavoritesCount(ctx context.Context, userID primitive.ObjectID) (int64, error)
 	IsFavorite(ctx context.Context, userID, carID primitive.ObjectID) (bool, error)
+	// FavoriteCarIDs returns which of carIDs the user has favorited
+	FavoriteCarIDs(ctx context.Context, userID primitive.ObjectID, carIDs []primitive.ObjectID) ([]primitive.ObjectID, error)
+	MoveFavorite(ctx context.Context, userID, carID, collectionID primitive.ObjectID) error
+	UpdateFavoriteNote(ctx context.Context, userID, carID primitive.ObjectID, note string) error
+
+	CreateCollection(ctx context.Context, collection *model.FavoriteCollection) error
+	GetUserCollections(ctx context.Context, userID primitive.ObjectID) ([]model.FavoriteCollection, error)
+	GetCollection(ctx context.Context, collectionID, userID primitive.ObjectID) (*model.FavoriteCollection, error)
+	GetCollectionFavorites(ctx context.Context, userID, collectionID primitive.ObjectID, params model.FavoriteListParams) (*model.FavoritesResponse, error)
+	RenameCollection(ctx context.Context, collectionID, userID primitive.ObjectID, name string) error
+	DeleteCollection(ctx context.Context, collectionID, userID primitive.ObjectID) error
+	ShareCollection(ctx context.Context, collectionID, userID primitive.ObjectID) (string, error)
+	UnshareCollection(ctx context.Context, collectionID, userID primitive.ObjectID) error
+	GetSharedCollection(ctx context.Context, token string) (*model.SharedCollectionResponse, error)
 }
 
+// ErrCollectionNotFound is returned when a favorite collection does not exist or belongs to another user
+var ErrCollectionNotFound = domain.NotFound("collection")
+
+const (
+	defaultFavoritesLimit = 50
+	maxFavoritesLimit     = 200
+)
+
 type MongoFavoriteRepository struct {
-	collection     *mongo.Collection
-	carsCollection *mongo.Collection
+	collection            *mongo.Collection
+	carsCollection        *mongo.Collection
+	collectionsCollection *mongo.Collection
 }
 
-func NewMongoFavoriteRepository(collection *mongo.Collection, carsCollection *mongo.Collection) *MongoFavoriteRepository {
+func NewMongoFavoriteRepository(collection *mongo.Collection, carsCollection *mongo.Collection, collectionsCollection *mongo.Collection) *MongoFavoriteRepository {
 	return &MongoFavoriteRepository{
-		collection:     collection,
-		carsCollection: carsCollection,
+		collection:            collection,
+		carsCollection:        carsCollection,
+		collectionsCollection: collectionsCollection,
 	}
 }
 
 // AddToFavorites adds a car to user's favorites
-func (r *MongoFavoriteRepository) AddToFavorites(ctx context.Context, userID, carID primitive.ObjectID) error {
-	// Check if already in favorites
-	exists, err := r.IsFavorite(ctx, userID, carID)
-	if err != nil {
-		return err
-	}
-	if exists {
-		return nil // Already in favorites
+func (r *MongoFavoriteRepository) AddToFavorites(ctx context.Context, favorite *model.Favorite) error {
+	if !favorite.CollectionID.IsZero() {
+		if _, err := r.GetCollection(ctx, favorite.CollectionID, favorite.UserID); err != nil {
+			return err
+		}
 	}
 
-	favorite := model.Favorite{
-		UserID:    userID,
-		CarID:     carID,
-		CreatedAt: time.Now(),
+	favorite.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, favorite)
+	if err != nil {
+		// The unique (user_id, car_id) index from migration 3 rejects duplicates
+		if mongo.IsDuplicateKeyError(err) {
+			return nil // Already in favorites
+		}
+		return err
 	}
 
-	_, err = r.collection.InsertOne(ctx, favorite)
-	return err
+	favorite.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
 }
 
 // RemoveFromFavorites removes a car from user's favorites
@@ -62,63 +94,413 @@ func (r *MongoFavoriteRepository) RemoveFromFavorites(ctx context.Context, userI
 	return err
 }
 
-// GetUserFavorites returns all favorites for a user with car details
-func (r *MongoFavoriteRepository) GetUserFavorites(ctx context.Context, userID primitive.ObjectID) ([]model.FavoriteWithCar, error) {
+// GetUserFavorites returns a page of the user's favorites with car details
+func (r *MongoFavoriteRepository) GetUserFavorites(ctx context.Context, userID primitive.ObjectID, params model.FavoriteListParams) (*model.FavoritesResponse, error) {
+	return r.findFavoritesPage(ctx, bson.M{"user_id": userID}, params)
+}
+
+// GetFavoritesCount returns the count of favorites for a user
+func (r *MongoFavoriteRepository) GetFavoritesCount(ctx context.Context, userID primitive.ObjectID) (int64, error) {
 	filter := bson.M{"user_id": userID}
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// IsFavorite checks if a car is in user's favorites
+func (r *MongoFavoriteRepository) IsFavorite(ctx context.Context, userID, carID primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"user_id": userID,
+		"car_id":  carID,
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *MongoFavoriteRepository) FavoriteCarIDs(ctx context.Context, userID primitive.ObjectID, carIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	filter := bson.M{
+		"user_id": userID,
+		"car_id":  bson.M{"$in": carIDs},
+	}
+
+	values, err := r.collection.Distinct(ctx, "car_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	favorited := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			favorited = append(favorited, id)
+		}
+	}
+	return favorited, nil
+}
 
-	cursor, err := r.collection.Find(ctx, filter)
+// MoveFavorite moves a favorite into a collection, or out of any collection when collectionID is zero
+func (r *MongoFavoriteRepository) MoveFavorite(ctx context.Context, userID, carID, collectionID primitive.ObjectID) error {
+	update := bson.M{"$unset": bson.M{"collection_id": ""}}
+	if !collectionID.IsZero() {
+		if _, err := r.GetCollection(ctx, collectionID, userID); err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"collection_id": collectionID}}
+	}
+
+	return r.updateFavorite(ctx, userID, carID, update)
+}
+
+// UpdateFavoriteNote sets the private note on a favorite
+func (r *MongoFavoriteRepository) UpdateFavoriteNote(ctx context.Context, userID, carID primitive.ObjectID, note string) error {
+	return r.updateFavorite(ctx, userID, carID, bson.M{"$set": bson.M{"note": note}})
+}
+
+// CreateCollection creates a new named favorite collection
+func (r *MongoFavoriteRepository) CreateCollection(ctx context.Context, collection *model.FavoriteCollection) error {
+	collection.CreatedAt = time.Now()
+	collection.UpdatedAt = time.Now()
+
+	result, err := r.collectionsCollection.InsertOne(ctx, collection)
+	if err != nil {
+		return mongoError(err, "collection")
+	}
+
+	collection.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetUserCollections returns all collections of a user sorted by name
+func (r *MongoFavoriteRepository) GetUserCollections(ctx context.Context, userID primitive.ObjectID) ([]model.FavoriteCollection, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collectionsCollection.Find(ctx, bson.M{"user_id": userID}, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var favorites []model.Favorite
-	if err = cursor.All(ctx, &favorites); err != nil {
+	collections := []model.FavoriteCollection{}
+	if err = cursor.All(ctx, &collections); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// GetCollection returns a collection owned by the user
+func (r *MongoFavoriteRepository) GetCollection(ctx context.Context, collectionID, userID primitive.ObjectID) (*model.FavoriteCollection, error) {
+	filter := bson.M{
+		"_id":     collectionID,
+		"user_id": userID,
+	}
+
+	var collection model.FavoriteCollection
+	err := r.collectionsCollection.FindOne(ctx, filter).Decode(&collection)
+	if err != nil {
+		return nil, mongoError(err, "collection")
+	}
+
+	return &collection, nil
+}
+
+// GetCollectionFavorites returns a page of the favorites in one of the user's collections
+func (r *MongoFavoriteRepository) GetCollectionFavorites(ctx context.Context, userID, collectionID primitive.ObjectID, params model.FavoriteListParams) (*model.FavoritesResponse, error) {
+	if _, err := r.GetCollection(ctx, collectionID, userID); err != nil {
 		return nil, err
 	}
 
-	// Fetch car details for each favorite
-	var favoritesWithCars []model.FavoriteWithCar
+	return r.findFavoritesPage(ctx, bson.M{"user_id": userID, "collection_id": collectionID}, params)
+}
+
+// RenameCollection changes the name of a collection
+func (r *MongoFavoriteRepository) RenameCollection(ctx context.Context, collectionID, userID primitive.ObjectID, name string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":       name,
+			"updated_at": time.Now(),
+		},
+	}
+
+	return r.updateCollection(ctx, collectionID, userID, update)
+}
+
+// DeleteCollection deletes a collection; its favorites are kept but no longer belong to a collection
+func (r *MongoFavoriteRepository) DeleteCollection(ctx context.Context, collectionID, userID primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":     collectionID,
+		"user_id": userID,
+	}
+
+	result, err := r.collectionsCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrCollectionNotFound
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "collection_id": collectionID},
+		bson.M{"$unset": bson.M{"collection_id": ""}},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "collection deleted but its favorites still point to it", "collection_id", collectionID.Hex(), "error", err)
+	}
+	return err
+}
+
+// ShareCollection generates an unguessable share token for a collection.
+// An already shared collection keeps its existing token.
+func (r *MongoFavoriteRepository) ShareCollection(ctx context.Context, collectionID, userID primitive.ObjectID) (string, error) {
+	collection, err := r.GetCollection(ctx, collectionID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if collection.ShareToken != "" {
+		return collection.ShareToken, nil
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return "", err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"share_token": token,
+			"updated_at":  time.Now(),
+		},
+	}
+
+	if err := r.updateCollection(ctx, collectionID, userID, update); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// UnshareCollection revokes the share token so the old link stops working
+func (r *MongoFavoriteRepository) UnshareCollection(ctx context.Context, collectionID, userID primitive.ObjectID) error {
+	update := bson.M{
+		"$unset": bson.M{"share_token": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+	}
+
+	return r.updateCollection(ctx, collectionID, userID, update)
+}
+
+// GetSharedCollection returns the read-only view of a collection by its share token
+func (r *MongoFavoriteRepository) GetSharedCollection(ctx context.Context, token string) (*model.SharedCollectionResponse, error) {
+	if token == "" {
+		return nil, ErrCollectionNotFound
+	}
+
+	var collection model.FavoriteCollection
+	err := r.collectionsCollection.FindOne(ctx, bson.M{"share_token": token}).Decode(&collection)
+	if err != nil {
+		return nil, mongoError(err, "collection")
+	}
+
+	filter := bson.M{"user_id": collection.UserID, "collection_id": collection.ID}
+	favorites, _, err := r.findFavoritesWithCars(ctx, filter, favoriteSortOrder(model.FavoriteSortNewest), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	cars := make([]model.Car, 0, len(favorites))
 	for _, fav := range favorites {
-		var car model.Car
-		err := r.carsCollection.FindOne(ctx, bson.M{"_id": fav.CarID}).Decode(&car)
-		if err != nil {
-			// If car not found, skip it
-			if err == mongo.ErrNoDocuments {
-				continue
-			}
-			return nil, err
-		}
+		cars = append(cars, *fav.Car)
+	}
+
+	return &model.SharedCollectionResponse{
+		Name:      collection.Name,
+		Cars:      cars,
+		UpdatedAt: collection.UpdatedAt,
+	}, nil
+}
+
+// findFavoritesPage loads one page of favorites matching filter
+func (r *MongoFavoriteRepository) findFavoritesPage(ctx context.Context, filter bson.M, params model.FavoriteListParams) (*model.FavoritesResponse, error) {
+	params = normalizeFavoriteListParams(params)
 
-		favoritesWithCars = append(favoritesWithCars, model.FavoriteWithCar{
-			ID:        fav.ID,
-			UserID:    fav.UserID,
-			CarID:     fav.CarID,
-			Car:       &car,
-			CreatedAt: fav.CreatedAt,
-		})
+	skip := int64((params.Page - 1) * params.Limit)
+	favorites, total, err := r.findFavoritesWithCars(ctx, filter, favoriteSortOrder(params.Sort), skip, int64(params.Limit))
+	if err != nil {
+		return nil, err
 	}
 
-	return favoritesWithCars, nil
+	return &model.FavoritesResponse{
+		Favorites:  favorites,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: (total + params.Limit - 1) / params.Limit,
+		Sort:       params.Sort,
+	}, nil
 }
 
-// GetFavoritesCount returns the count of favorites for a user
-func (r *MongoFavoriteRepository) GetFavoritesCount(ctx context.Context, userID primitive.ObjectID) (int64, error) {
-	filter := bson.M{"user_id": userID}
-	return r.collection.CountDocuments(ctx, filter)
+// findFavoritesWithCars joins the favorites matching filter with their cars in a
+// single aggregation. Favorites whose car no longer exists are skipped.
+// It returns the requested page (all of them when limit is 0) and the total count.
+func (r *MongoFavoriteRepository) findFavoritesWithCars(ctx context.Context, filter bson.M, sort bson.D, skip, limit int64) ([]model.FavoriteWithCar, int, error) {
+	page := bson.A{bson.M{"$skip": skip}}
+	if limit > 0 {
+		page = append(page, bson.M{"$limit": limit})
+	}
+	join := bson.A{
+		bson.M{"$lookup": bson.M{
+			"from":         r.carsCollection.Name(),
+			"localField":   "car_id",
+			"foreignField": "_id",
+			"as":           "car",
+		}},
+		bson.M{"$unwind": "$car"}, // drops favorites whose car was deleted
+	}
+
+	pipeline := bson.A{bson.M{"$match": filter}}
+	if sortsByCar(sort) {
+		// Sorting by price needs every car joined first
+		pipeline = append(pipeline, join...)
+		pipeline = append(pipeline,
+			bson.M{"$sort": sort},
+			bson.M{"$facet": bson.M{
+				"items": page,
+				"total": bson.A{bson.M{"$count": "count"}},
+			}},
+		)
+	} else {
+		// Date sorts only need to know which cars still exist before paging,
+		// so only one page of full cars is joined
+		pipeline = append(pipeline,
+			bson.M{"$lookup": bson.M{
+				"from":         r.carsCollection.Name(),
+				"localField":   "car_id",
+				"foreignField": "_id",
+				"pipeline":     bson.A{bson.M{"$project": bson.M{"_id": 1}}},
+				"as":           "car",
+			}},
+			bson.M{"$match": bson.M{"car": bson.M{"$ne": bson.A{}}}},
+			bson.M{"$sort": sort},
+			bson.M{"$facet": bson.M{
+				"items": append(page, join...),
+				"total": bson.A{bson.M{"$count": "count"}},
+			}},
+		)
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Items []model.FavoriteWithCar `bson:"items"`
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, 0, err
+	}
+
+	favorites := []model.FavoriteWithCar{}
+	total := 0
+	if len(result) > 0 {
+		if result[0].Items != nil {
+			favorites = result[0].Items
+		}
+		if len(result[0].Total) > 0 {
+			total = result[0].Total[0].Count
+		}
+	}
+
+	return favorites, total, nil
 }
 
-// IsFavorite checks if a car is in user's favorites
-func (r *MongoFavoriteRepository) IsFavorite(ctx context.Context, userID, carID primitive.ObjectID) (bool, error) {
+// updateFavorite applies update to the user's favorite for carID
+func (r *MongoFavoriteRepository) updateFavorite(ctx context.Context, userID, carID primitive.ObjectID, update bson.M) error {
 	filter := bson.M{
 		"user_id": userID,
 		"car_id":  carID,
 	}
 
-	count, err := r.collection.CountDocuments(ctx, filter)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	return count > 0, nil
+	if result.MatchedCount == 0 {
+		return domain.NotFound("favorite")
+	}
+
+	return nil
+}
+
+// updateCollection applies update to a collection owned by the user
+func (r *MongoFavoriteRepository) updateCollection(ctx context.Context, collectionID, userID primitive.ObjectID, update bson.M) error {
+	filter := bson.M{
+		"_id":     collectionID,
+		"user_id": userID,
+	}
+
+	result, err := r.collectionsCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+// newShareToken returns a random URL-safe token for share links
+func newShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// normalizeFavoriteListParams fills in defaults and clamps the page size
+func normalizeFavoriteListParams(params model.FavoriteListParams) model.FavoriteListParams {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = defaultFavoritesLimit
+	}
+	if params.Limit > maxFavoritesLimit {
+		params.Limit = maxFavoritesLimit
+	}
+	if !model.ValidFavoriteSort(params.Sort) {
+		params.Sort = model.FavoriteSortNewest
+	}
+	return params
+}
+
+// sortsByCar reports whether sort orders by a field of the joined car
+func sortsByCar(sort bson.D) bool {
+	return len(sort) > 0 && strings.HasPrefix(sort[0].Key, "car.")
+}
+
+// favoriteSortOrder maps a favorite sort option to a sort document over the joined car
+func favoriteSortOrder(sort string) bson.D {
+	switch sort {
+	case model.FavoriteSortOldest:
+		return bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}
+	case model.FavoriteSortPriceAsc:
+		return bson.D{{Key: "car.price", Value: 1}, {Key: "_id", Value: 1}}
+	case model.FavoriteSortPriceDesc:
+		return bson.D{{Key: "car.price", Value: -1}, {Key: "_id", Value: 1}}
+	}
+	return bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
 }
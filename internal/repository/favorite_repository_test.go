@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"github.com/teamserik/online-car-store/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BenchmarkUserFavorites compares loading favorites with one FindOne per
+// favorite, as before the $lookup pipeline, with GetUserFavorites.
+// It needs TEST_MONGO_URI:
+//
+//	TEST_MONGO_URI=mongodb://localhost:27017 go test -run '^$' -bench UserFavorites ./internal/repository
+func BenchmarkUserFavorites(b *testing.B) {
+	db := repositorytest.NewMongoDatabase(b)
+	cars, favorites := db.Collection("cars"), db.Collection("favorites")
+	repo := repository.NewMongoFavoriteRepository(favorites, cars, db.Collection("favorite_collections"))
+
+	for _, n := range []int{10, 200} {
+		ctx := context.Background()
+		userID := seedFavorites(b, repository.NewMongoCarRepository(cars), repo, n)
+
+		b.Run(fmt.Sprintf("FindOneLoop/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := findFavoritesLoop(ctx, favorites, cars, userID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Lookup/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetUserFavorites(ctx, userID, model.FavoriteListParams{Limit: n}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("LookupPriceSort/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetUserFavorites(ctx, userID, model.FavoriteListParams{Limit: n, Sort: model.FavoriteSortPriceAsc}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("LookupFirstPage/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetUserFavorites(ctx, userID, model.FavoriteListParams{Limit: 10}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// seedFavorites lists n cars and favorites all of them for a new user
+func seedFavorites(b *testing.B, cars repository.CarRepository, favorites repository.FavoriteRepository, n int) primitive.ObjectID {
+	b.Helper()
+
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+	for i := 0; i < n; i++ {
+		car := &model.Car{Make: "Toyota", Model: "RAV4", Year: 2021, Price: float64(10000 + i)}
+		if err := cars.Create(ctx, car); err != nil {
+			b.Fatalf("Create car: %v", err)
+		}
+		if err := favorites.AddToFavorites(ctx, &model.Favorite{UserID: userID, CarID: car.ID}); err != nil {
+			b.Fatalf("AddToFavorites: %v", err)
+		}
+	}
+	return userID
+}
+
+// findFavoritesLoop is how favorites were loaded before the $lookup pipeline
+func findFavoritesLoop(ctx context.Context, favorites, cars *mongo.Collection, userID primitive.ObjectID) ([]model.FavoriteWithCar, error) {
+	cursor, err := favorites.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	var found []model.Favorite
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	var withCars []model.FavoriteWithCar
+	for _, fav := range found {
+		var car model.Car
+		err := cars.FindOne(ctx, bson.M{"_id": fav.CarID}).Decode(&car)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		withCars = append(withCars, model.FavoriteWithCar{
+			ID:           fav.ID,
+			UserID:       fav.UserID,
+			CarID:        fav.CarID,
+			CollectionID: fav.CollectionID,
+			Note:         fav.Note,
+			Car:          &car,
+			CreatedAt:    fav.CreatedAt,
+		})
+	}
+	return withCars, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GarageRepository interface {
+	AddToGarage(ctx context.Context, item *model.GarageItem) error
+	RemoveFromGarage(ctx context.Context, userID, carID primitive.ObjectID) error
+	GetUserGarage(ctx context.Context, userID primitive.ObjectID) ([]model.GarageItemWithCar, error)
+	ImportGarage(ctx context.Context, userID primitive.ObjectID, items []model.GarageItem) (int, error)
+}
+
+type MongoGarageRepository struct {
+	collection     *mongo.Collection
+	carsCollection *mongo.Collection
+}
+
+func NewMongoGarageRepository(collection *mongo.Collection, carsCollection *mongo.Collection) *MongoGarageRepository {
+	return &MongoGarageRepository{
+		collection:     collection,
+		carsCollection: carsCollection,
+	}
+}
+
+// AddToGarage adds a car to the user's garage, remembering its current price.
+// Adding a car that is already in the garage is a no-op.
+func (r *MongoGarageRepository) AddToGarage(ctx context.Context, item *model.GarageItem) error {
+	if item.AddedAt.IsZero() {
+		item.AddedAt = time.Now()
+	}
+
+	filter := bson.M{
+		"user_id": item.UserID,
+		"car_id":  item.CarID,
+	}
+
+	update := bson.M{"$setOnInsert": bson.M{
+		"user_id":          item.UserID,
+		"car_id":           item.CarID,
+		"price_when_added": item.PriceWhenAdded,
+		"added_at":         item.AddedAt,
+	}}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	return mongoError(r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(item), "garage car")
+}
+
+// RemoveFromGarage removes a car from the user's garage
+func (r *MongoGarageRepository) RemoveFromGarage(ctx context.Context, userID, carID primitive.ObjectID) error {
+	filter := bson.M{
+		"user_id": userID,
+		"car_id":  carID,
+	}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.NotFound("garage car")
+	}
+
+	return nil
+}
+
+// GetUserGarage returns the user's garage with live car data, newest first
+func (r *MongoGarageRepository) GetUserGarage(ctx context.Context, userID primitive.ObjectID) ([]model.GarageItemWithCar, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "added_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []model.GarageItem
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	// Load all cars with a single query
+	carIDs := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		carIDs = append(carIDs, item.CarID)
+	}
+
+	carsCursor, err := r.carsCollection.Find(ctx, bson.M{"_id": bson.M{"$in": carIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer carsCursor.Close(ctx)
+
+	var cars []model.Car
+	if err = carsCursor.All(ctx, &cars); err != nil {
+		return nil, err
+	}
+
+	carsByID := make(map[primitive.ObjectID]*model.Car, len(cars))
+	for i := range cars {
+		carsByID[cars[i].ID] = &cars[i]
+	}
+
+	garage := make([]model.GarageItemWithCar, 0, len(items))
+	for _, item := range items {
+		entry := model.GarageItemWithCar{
+			ID:             item.ID,
+			CarID:          item.CarID,
+			PriceWhenAdded: item.PriceWhenAdded,
+			AddedAt:        item.AddedAt,
+		}
+
+		car, ok := carsByID[item.CarID]
+		if !ok {
+			// The listing is gone: the car was sold or removed
+			entry.Sold = true
+		} else {
+			entry.Car = car
+			entry.PriceDelta = car.Price - item.PriceWhenAdded
+			entry.PriceChanged = entry.PriceDelta != 0
+		}
+
+		garage = append(garage, entry)
+	}
+
+	return garage, nil
+}
+
+// ImportGarage merges items into the user's garage, skipping cars that are
+// already there or no longer listed. It returns the number of cars added.
+func (r *MongoGarageRepository) ImportGarage(ctx context.Context, userID primitive.ObjectID, items []model.GarageItem) (int, error) {
+	carIDs := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		carIDs = append(carIDs, item.CarID)
+	}
+
+	// Only import cars that still exist
+	existing, err := r.carsCollection.Distinct(ctx, "_id", bson.M{"_id": bson.M{"$in": carIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	listed := make(map[primitive.ObjectID]bool, len(existing))
+	for _, id := range existing {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			listed[oid] = true
+		}
+	}
+
+	var models []mongo.WriteModel
+	for _, item := range items {
+		if !listed[item.CarID] {
+			continue
+		}
+
+		if item.AddedAt.IsZero() {
+			item.AddedAt = time.Now()
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": userID, "car_id": item.CarID}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{
+				"user_id":          userID,
+				"car_id":           item.CarID,
+				"price_when_added": item.PriceWhenAdded,
+				"added_at":         item.AddedAt,
+			}}).
+			SetUpsert(true))
+	}
+
+	if len(models) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.UpsertedCount), nil
+}
@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryCarRepository is a thread-safe in-memory CarRepository for tests
+type MemoryCarRepository struct {
+	mu   sync.RWMutex
+	cars map[primitive.ObjectID]model.Car
+}
+
+var _ CarRepository = (*MemoryCarRepository)(nil)
+
+func NewMemoryCarRepository() *MemoryCarRepository {
+	return &MemoryCarRepository{
+		cars: make(map[primitive.ObjectID]model.Car),
+	}
+}
+
+func (r *MemoryCarRepository) Create(ctx context.Context, car *model.Car) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if car.StockNumber != "" {
+		for _, existing := range r.cars {
+			if existing.DealerID == car.DealerID && existing.StockNumber == car.StockNumber {
+				return domain.Conflict("car with this stock number already exists")
+			}
+		}
+	}
+
+	car.ID = primitive.NewObjectID()
+	car.Version = 1
+	car.CreatedAt = time.Now()
+	car.UpdatedAt = time.Now()
+
+	r.cars[car.ID] = *car
+	return nil
+}
+
+func (r *MemoryCarRepository) GetByID(ctx context.Context, id string) (*model.Car, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	objectID, err := parseObjectID(id, "car")
+	if err != nil {
+		return nil, err
+	}
+
+	car, ok := r.get(objectID)
+	if !ok {
+		return nil, domain.NotFound("car")
+	}
+
+	return &car, nil
+}
+
+func (r *MemoryCarRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.Car, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cars := []*model.Car{}
+	for id, car := range r.cars {
+		if slices.Contains(ids, id) {
+			cars = append(cars, &car)
+		}
+	}
+
+	return cars, nil
+}
+
+func (r *MemoryCarRepository) List(ctx context.Context, filter *model.FilterParams) ([]*model.Car, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cars := []*model.Car{}
+	for _, car := range r.cars {
+		if matchesCarFilter(car, filter) {
+			c := car
+			cars = append(cars, &c)
+		}
+	}
+
+	sortBy := ""
+	if filter != nil {
+		sortBy = filter.Sort
+	}
+
+	sort.SliceStable(cars, func(i, j int) bool {
+		a, b := cars[i], cars[j]
+		switch sortBy {
+		case model.CarSortNewest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+		case model.CarSortPriceAsc:
+			if a.Price != b.Price {
+				return a.Price < b.Price
+			}
+		case model.CarSortPriceDesc:
+			if a.Price != b.Price {
+				return a.Price > b.Price
+			}
+		case model.CarSortRating:
+			if a.RatingAvg != b.RatingAvg {
+				return a.RatingAvg > b.RatingAvg
+			}
+			if a.RatingCount != b.RatingCount {
+				return a.RatingCount > b.RatingCount
+			}
+		}
+		// Insertion order, like Mongo's natural order
+		return a.ID.Hex() < b.ID.Hex()
+	})
+
+	if filter != nil && filter.Limit > 0 {
+		start := min((max(filter.Page, 1)-1)*filter.Limit, len(cars))
+		cars = cars[start:min(start+filter.Limit, len(cars))]
+	}
+	return cars, nil
+}
+
+func (r *MemoryCarRepository) Count(ctx context.Context, filter *model.FilterParams) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, car := range r.cars {
+		if matchesCarFilter(car, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryCarRepository) Update(ctx context.Context, id string, input model.UpdateCarInput, version int64) (*model.Car, error) {
+	return r.modify(ctx, id, version, func(car *model.Car) {
+		setListing(car, input)
+	})
+}
+
+// setListing mirrors listingFields
+func setListing(car *model.Car, input model.UpdateCarInput) {
+	car.Make = input.Make
+	car.Model = input.Model
+	car.Year = input.Year
+	car.Price = input.Price
+	car.Mileage = input.Mileage
+	car.BodyType = input.BodyType
+	car.FuelType = input.FuelType
+	car.Transmission = input.Transmission
+	car.Color = input.Color
+	car.HorsePower = input.HorsePower
+	car.EngineSize = input.EngineSize
+	car.Description = input.Description
+	car.ImageURL = input.ImageURL
+}
+
+func (r *MemoryCarRepository) Patch(ctx context.Context, id string, input model.PatchCarInput, version int64) (*model.Car, error) {
+	return r.modify(ctx, id, version, func(car *model.Car) {
+		patch(&car.Make, input.Make)
+		patch(&car.Model, input.Model)
+		patch(&car.Year, input.Year)
+		patch(&car.Price, input.Price)
+		patch(&car.Mileage, input.Mileage)
+		patch(&car.BodyType, input.BodyType)
+		patch(&car.FuelType, input.FuelType)
+		patch(&car.Transmission, input.Transmission)
+		patch(&car.Color, input.Color)
+		patch(&car.HorsePower, input.HorsePower)
+		patch(&car.EngineSize, input.EngineSize)
+		patch(&car.Description, input.Description)
+		patch(&car.ImageURL, input.ImageURL)
+	})
+}
+
+// modify applies change to the stored car, bumps UpdatedAt and Version and returns a copy
+func (r *MemoryCarRepository) modify(ctx context.Context, id string, version int64, change func(*model.Car)) (*model.Car, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	objectID, err := parseObjectID(id, "car")
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	car, ok := r.cars[objectID]
+	if !ok {
+		return nil, domain.NotFound("car")
+	}
+	if version != AnyVersion && car.Version != version {
+		return nil, domain.PreconditionFailed("car")
+	}
+
+	change(&car)
+	car.UpdatedAt = time.Now()
+	car.Version++
+
+	r.cars[objectID] = car
+	return &car, nil
+}
+
+// patch overwrites *dst when the patch value was provided
+func patch[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func (r *MemoryCarRepository) Delete(ctx context.Context, id string, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	objectID, err := parseObjectID(id, "car")
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	car, ok := r.cars[objectID]
+	if !ok {
+		return domain.NotFound("car")
+	}
+	if version != AnyVersion && car.Version != version {
+		return domain.PreconditionFailed("car")
+	}
+
+	delete(r.cars, objectID)
+	return nil
+}
+
+func (r *MemoryCarRepository) UpsertByStockNumber(ctx context.Context, dealerID primitive.ObjectID, stockNumber string, input model.UpdateCarInput) (*model.Car, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, car := range r.cars {
+		if car.DealerID == dealerID && car.StockNumber == stockNumber {
+			setListing(&car, input)
+			car.UpdatedAt = now
+			car.Version++
+			r.cars[id] = car
+			return &car, false, nil
+		}
+	}
+
+	car := model.Car{
+		ID:          primitive.NewObjectID(),
+		DealerID:    dealerID,
+		StockNumber: stockNumber,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	setListing(&car, input)
+	r.cars[car.ID] = car
+	return &car, true, nil
+}
+
+// get returns a copy of the car, used by the other in-memory repositories to join cars
+func (r *MemoryCarRepository) get(id primitive.ObjectID) (model.Car, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	car, ok := r.cars[id]
+	return car, ok
+}
+
+// setRating stores the denormalized rating maintained by MemoryReviewRepository
+func (r *MemoryCarRepository) setRating(id primitive.ObjectID, stats *model.RatingStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	car, ok := r.cars[id]
+	if !ok {
+		return
+	}
+
+	car.RatingAvg = stats.AverageRating
+	car.RatingCount = stats.TotalReviews
+	car.Version++ // the representation changed, so cached ETags must not match
+	r.cars[id] = car
+}
+
+// matchesCarFilter mirrors the query built by mongoCarRepository.List
+func matchesCarFilter(car model.Car, filter *model.FilterParams) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.MinPrice != nil && car.Price < *filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice != nil && car.Price > *filter.MaxPrice {
+		return false
+	}
+	if filter.Make != nil && car.Make != *filter.Make {
+		return false
+	}
+	if filter.BodyType != nil && car.BodyType != *filter.BodyType {
+		return false
+	}
+	if filter.FuelType != nil && car.FuelType != *filter.FuelType {
+		return false
+	}
+	if filter.Transmission != nil && car.Transmission != *filter.Transmission {
+		return false
+	}
+	if filter.DealerID != nil && car.DealerID != *filter.DealerID {
+		return false
+	}
+	if filter.MinYear != nil && car.Year < *filter.MinYear {
+		return false
+	}
+	if filter.MaxYear != nil && car.Year > *filter.MaxYear {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,455 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryFavoriteRepository is a thread-safe in-memory FavoriteRepository for tests.
+// Cars are joined from the given MemoryCarRepository.
+type MemoryFavoriteRepository struct {
+	mu          sync.RWMutex
+	cars        *MemoryCarRepository
+	favorites   map[primitive.ObjectID]model.Favorite
+	collections map[primitive.ObjectID]model.FavoriteCollection
+}
+
+var _ FavoriteRepository = (*MemoryFavoriteRepository)(nil)
+
+func NewMemoryFavoriteRepository(cars *MemoryCarRepository) *MemoryFavoriteRepository {
+	return &MemoryFavoriteRepository{
+		cars:        cars,
+		favorites:   make(map[primitive.ObjectID]model.Favorite),
+		collections: make(map[primitive.ObjectID]model.FavoriteCollection),
+	}
+}
+
+func (r *MemoryFavoriteRepository) AddToFavorites(ctx context.Context, favorite *model.Favorite) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !favorite.CollectionID.IsZero() {
+		if _, ok := r.ownedCollection(favorite.CollectionID, favorite.UserID); !ok {
+			return ErrCollectionNotFound
+		}
+	}
+
+	if _, ok := r.findFavorite(favorite.UserID, favorite.CarID); ok {
+		return nil // Already in favorites
+	}
+
+	favorite.ID = primitive.NewObjectID()
+	favorite.CreatedAt = time.Now()
+	r.favorites[favorite.ID] = *favorite
+	return nil
+}
+
+func (r *MemoryFavoriteRepository) RemoveFromFavorites(ctx context.Context, userID, carID primitive.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fav, ok := r.findFavorite(userID, carID); ok {
+		delete(r.favorites, fav.ID)
+	}
+	return nil
+}
+
+func (r *MemoryFavoriteRepository) GetUserFavorites(ctx context.Context, userID primitive.ObjectID, params model.FavoriteListParams) (*model.FavoritesResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.favoritesPage(func(f model.Favorite) bool { return f.UserID == userID }, params), nil
+}
+
+func (r *MemoryFavoriteRepository) GetFavoritesCount(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, fav := range r.favorites {
+		if fav.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryFavoriteRepository) IsFavorite(ctx context.Context, userID, carID primitive.ObjectID) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.findFavorite(userID, carID)
+	return ok, nil
+}
+
+func (r *MemoryFavoriteRepository) FavoriteCarIDs(ctx context.Context, userID primitive.ObjectID, carIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	favorited := []primitive.ObjectID{}
+	for _, fav := range r.favorites {
+		if fav.UserID == userID && slices.Contains(carIDs, fav.CarID) {
+			favorited = append(favorited, fav.CarID)
+		}
+	}
+	return favorited, nil
+}
+
+func (r *MemoryFavoriteRepository) MoveFavorite(ctx context.Context, userID, carID, collectionID primitive.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !collectionID.IsZero() {
+		if _, ok := r.ownedCollection(collectionID, userID); !ok {
+			return ErrCollectionNotFound
+		}
+	}
+
+	fav, ok := r.findFavorite(userID, carID)
+	if !ok {
+		return domain.NotFound("favorite")
+	}
+
+	fav.CollectionID = collectionID
+	r.favorites[fav.ID] = fav
+	return nil
+}
+
+func (r *MemoryFavoriteRepository) UpdateFavoriteNote(ctx context.Context, userID, carID primitive.ObjectID, note string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fav, ok := r.findFavorite(userID, carID)
+	if !ok {
+		return domain.NotFound("favorite")
+	}
+
+	fav.Note = note
+	r.favorites[fav.ID] = fav
+	return nil
+}
+
+func (r *MemoryFavoriteRepository) CreateCollection(ctx context.Context, collection *model.FavoriteCollection) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	collection.ID = primitive.NewObjectID()
+	collection.CreatedAt = time.Now()
+	collection.UpdatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collections[collection.ID] = *collection
+	return nil
+}
+
+func (r *MemoryFavoriteRepository) GetUserCollections(ctx context.Context, userID primitive.ObjectID) ([]model.FavoriteCollection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collections := []model.FavoriteCollection{}
+	for _, c := range r.collections {
+		if c.UserID == userID {
+			collections = append(collections, c)
+		}
+	}
+
+	sort.SliceStable(collections, func(i, j int) bool {
+		return collections[i].Name < collections[j].Name
+	})
+
+	return collections, nil
+}
+
+func (r *MemoryFavoriteRepository) GetCollection(ctx context.Context, collectionID, userID primitive.ObjectID) (*model.FavoriteCollection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collection, ok := r.ownedCollection(collectionID, userID)
+	if !ok {
+		return nil, ErrCollectionNotFound
+	}
+	return &collection, nil
+}
+
+func (r *MemoryFavoriteRepository) GetCollectionFavorites(ctx context.Context, userID, collectionID primitive.ObjectID, params model.FavoriteListParams) (*model.FavoritesResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.ownedCollection(collectionID, userID); !ok {
+		return nil, ErrCollectionNotFound
+	}
+
+	match := func(f model.Favorite) bool { return f.UserID == userID && f.CollectionID == collectionID }
+	return r.favoritesPage(match, params), nil
+}
+
+func (r *MemoryFavoriteRepository) RenameCollection(ctx context.Context, collectionID, userID primitive.ObjectID, name string) error {
+	return r.updateCollection(ctx, collectionID, userID, func(c *model.FavoriteCollection) {
+		c.Name = name
+	})
+}
+
+func (r *MemoryFavoriteRepository) DeleteCollection(ctx context.Context, collectionID, userID primitive.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.ownedCollection(collectionID, userID); !ok {
+		return ErrCollectionNotFound
+	}
+	delete(r.collections, collectionID)
+
+	for id, fav := range r.favorites {
+		if fav.UserID == userID && fav.CollectionID == collectionID {
+			fav.CollectionID = primitive.NilObjectID
+			r.favorites[id] = fav
+		}
+	}
+	return nil
+}
+
+func (r *MemoryFavoriteRepository) ShareCollection(ctx context.Context, collectionID, userID primitive.ObjectID) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	collection, ok := r.ownedCollection(collectionID, userID)
+	if !ok {
+		return "", ErrCollectionNotFound
+	}
+
+	if collection.ShareToken != "" {
+		return collection.ShareToken, nil
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return "", err
+	}
+
+	collection.ShareToken = token
+	collection.UpdatedAt = time.Now()
+	r.collections[collectionID] = collection
+	return token, nil
+}
+
+func (r *MemoryFavoriteRepository) UnshareCollection(ctx context.Context, collectionID, userID primitive.ObjectID) error {
+	return r.updateCollection(ctx, collectionID, userID, func(c *model.FavoriteCollection) {
+		c.ShareToken = ""
+	})
+}
+
+func (r *MemoryFavoriteRepository) GetSharedCollection(ctx context.Context, token string) (*model.SharedCollectionResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if token == "" {
+		return nil, ErrCollectionNotFound
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, collection := range r.collections {
+		if collection.ShareToken != token {
+			continue
+		}
+
+		match := func(f model.Favorite) bool {
+			return f.UserID == collection.UserID && f.CollectionID == collection.ID
+		}
+
+		favorites := r.joinedFavorites(match, model.FavoriteSortNewest)
+		cars := make([]model.Car, 0, len(favorites))
+		for _, fav := range favorites {
+			cars = append(cars, *fav.Car)
+		}
+
+		return &model.SharedCollectionResponse{
+			Name:      collection.Name,
+			Cars:      cars,
+			UpdatedAt: collection.UpdatedAt,
+		}, nil
+	}
+
+	return nil, ErrCollectionNotFound
+}
+
+// favoritesPage returns one page of the favorites matching match. Callers hold r.mu.
+func (r *MemoryFavoriteRepository) favoritesPage(match func(model.Favorite) bool, params model.FavoriteListParams) *model.FavoritesResponse {
+	params = normalizeFavoriteListParams(params)
+
+	favorites := r.joinedFavorites(match, params.Sort)
+	total := len(favorites)
+
+	start := (params.Page - 1) * params.Limit
+	if start > total {
+		start = total
+	}
+	end := start + params.Limit
+	if end > total {
+		end = total
+	}
+
+	return &model.FavoritesResponse{
+		Favorites:  favorites[start:end],
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: (total + params.Limit - 1) / params.Limit,
+		Sort:       params.Sort,
+	}
+}
+
+// joinedFavorites joins the matching favorites with their cars, skipping deleted cars,
+// and sorts them like favoriteSortOrder. Callers hold r.mu.
+func (r *MemoryFavoriteRepository) joinedFavorites(match func(model.Favorite) bool, sortBy string) []model.FavoriteWithCar {
+	favorites := []model.FavoriteWithCar{}
+	for _, fav := range r.favorites {
+		if !match(fav) {
+			continue
+		}
+
+		car, ok := r.cars.get(fav.CarID)
+		if !ok {
+			continue
+		}
+
+		favorites = append(favorites, model.FavoriteWithCar{
+			ID:           fav.ID,
+			UserID:       fav.UserID,
+			CarID:        fav.CarID,
+			CollectionID: fav.CollectionID,
+			Note:         fav.Note,
+			Car:          &car,
+			CreatedAt:    fav.CreatedAt,
+		})
+	}
+
+	sort.SliceStable(favorites, func(i, j int) bool {
+		a, b := favorites[i], favorites[j]
+		switch sortBy {
+		case model.FavoriteSortOldest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID.Hex() < b.ID.Hex()
+		case model.FavoriteSortPriceAsc:
+			if a.Car.Price != b.Car.Price {
+				return a.Car.Price < b.Car.Price
+			}
+			return a.ID.Hex() < b.ID.Hex()
+		case model.FavoriteSortPriceDesc:
+			if a.Car.Price != b.Car.Price {
+				return a.Car.Price > b.Car.Price
+			}
+			return a.ID.Hex() < b.ID.Hex()
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID.Hex() > b.ID.Hex()
+	})
+
+	return favorites
+}
+
+// findFavorite looks up the user's favorite for a car. Callers hold r.mu.
+func (r *MemoryFavoriteRepository) findFavorite(userID, carID primitive.ObjectID) (model.Favorite, bool) {
+	for _, fav := range r.favorites {
+		if fav.UserID == userID && fav.CarID == carID {
+			return fav, true
+		}
+	}
+	return model.Favorite{}, false
+}
+
+// ownedCollection returns the collection if it belongs to the user. Callers hold r.mu.
+func (r *MemoryFavoriteRepository) ownedCollection(collectionID, userID primitive.ObjectID) (model.FavoriteCollection, bool) {
+	collection, ok := r.collections[collectionID]
+	if !ok || collection.UserID != userID {
+		return model.FavoriteCollection{}, false
+	}
+	return collection, true
+}
+
+// updateCollection applies update to a collection owned by the user
+func (r *MemoryFavoriteRepository) updateCollection(ctx context.Context, collectionID, userID primitive.ObjectID, update func(*model.FavoriteCollection)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	collection, ok := r.ownedCollection(collectionID, userID)
+	if !ok {
+		return ErrCollectionNotFound
+	}
+
+	update(&collection)
+	collection.UpdatedAt = time.Now()
+	r.collections[collectionID] = collection
+	return nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryGarageRepository is a thread-safe in-memory GarageRepository for tests.
+// Cars are joined from the given MemoryCarRepository.
+type MemoryGarageRepository struct {
+	mu    sync.RWMutex
+	cars  *MemoryCarRepository
+	items map[primitive.ObjectID]model.GarageItem
+}
+
+var _ GarageRepository = (*MemoryGarageRepository)(nil)
+
+func NewMemoryGarageRepository(cars *MemoryCarRepository) *MemoryGarageRepository {
+	return &MemoryGarageRepository{
+		cars:  cars,
+		items: make(map[primitive.ObjectID]model.GarageItem),
+	}
+}
+
+func (r *MemoryGarageRepository) AddToGarage(ctx context.Context, item *model.GarageItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.findItem(item.UserID, item.CarID); ok {
+		*item = existing
+		return nil
+	}
+
+	r.insert(item)
+	return nil
+}
+
+func (r *MemoryGarageRepository) RemoveFromGarage(ctx context.Context, userID, carID primitive.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.findItem(userID, carID)
+	if !ok {
+		return domain.NotFound("garage car")
+	}
+
+	delete(r.items, item.ID)
+	return nil
+}
+
+func (r *MemoryGarageRepository) GetUserGarage(ctx context.Context, userID primitive.ObjectID) ([]model.GarageItemWithCar, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	garage := []model.GarageItemWithCar{}
+	for _, item := range r.items {
+		if item.UserID != userID {
+			continue
+		}
+
+		entry := model.GarageItemWithCar{
+			ID:             item.ID,
+			CarID:          item.CarID,
+			PriceWhenAdded: item.PriceWhenAdded,
+			AddedAt:        item.AddedAt,
+		}
+
+		if car, ok := r.cars.get(item.CarID); ok {
+			entry.Car = &car
+			entry.PriceDelta = car.Price - item.PriceWhenAdded
+			entry.PriceChanged = entry.PriceDelta != 0
+		} else {
+			entry.Sold = true
+		}
+
+		garage = append(garage, entry)
+	}
+
+	sort.SliceStable(garage, func(i, j int) bool {
+		return garage[i].AddedAt.After(garage[j].AddedAt)
+	})
+
+	return garage, nil
+}
+
+func (r *MemoryGarageRepository) ImportGarage(ctx context.Context, userID primitive.ObjectID, items []model.GarageItem) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	imported := 0
+	for _, item := range items {
+		if _, listed := r.cars.get(item.CarID); !listed {
+			continue
+		}
+		if _, ok := r.findItem(userID, item.CarID); ok {
+			continue
+		}
+
+		item.UserID = userID
+		r.insert(&item)
+		imported++
+	}
+
+	return imported, nil
+}
+
+// insert stores a new garage item. Callers hold r.mu.
+func (r *MemoryGarageRepository) insert(item *model.GarageItem) {
+	item.ID = primitive.NewObjectID()
+	if item.AddedAt.IsZero() {
+		item.AddedAt = time.Now()
+	}
+	r.items[item.ID] = *item
+}
+
+// findItem looks up the user's garage item for a car. Callers hold r.mu.
+func (r *MemoryGarageRepository) findItem(userID, carID primitive.ObjectID) (model.GarageItem, bool) {
+	for _, item := range r.items {
+		if item.UserID == userID && item.CarID == carID {
+			return item, true
+		}
+	}
+	return model.GarageItem{}, false
+}
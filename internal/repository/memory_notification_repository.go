@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryNotificationRepository is a thread-safe in-memory NotificationRepository for tests
+type MemoryNotificationRepository struct {
+	mu            sync.RWMutex
+	notifications map[primitive.ObjectID]model.Notification
+}
+
+var _ NotificationRepository = (*MemoryNotificationRepository)(nil)
+
+func NewMemoryNotificationRepository() *MemoryNotificationRepository {
+	return &MemoryNotificationRepository{
+		notifications: make(map[primitive.ObjectID]model.Notification),
+	}
+}
+
+func (r *MemoryNotificationRepository) CreateNotification(ctx context.Context, notification *model.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	notification.ID = primitive.NewObjectID()
+	notification.Read = false
+	notification.CreatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (r *MemoryNotificationRepository) GetUserNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) ([]model.Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notifications := []model.Notification{}
+	for _, n := range r.notifications {
+		if n.UserID == userID && (!unreadOnly || !n.Read) {
+			notifications = append(notifications, n)
+		}
+	}
+
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+
+	return notifications, nil
+}
+
+func (r *MemoryNotificationRepository) MarkAsRead(ctx context.Context, notificationID, userID primitive.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n, ok := r.notifications[notificationID]
+	if !ok || n.UserID != userID {
+		return domain.NotFound("notification")
+	}
+
+	n.Read = true
+	r.notifications[notificationID] = n
+	return nil
+}
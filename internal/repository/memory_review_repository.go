@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reviewVoteKey identifies a user's vote on a review
+type reviewVoteKey struct {
+	reviewID primitive.ObjectID
+	userID   primitive.ObjectID
+}
+
+// MemoryReviewRepository is a thread-safe in-memory ReviewRepository for tests.
+// It keeps the denormalized car rating in the given MemoryCarRepository up to date.
+type MemoryReviewRepository struct {
+	mu      sync.RWMutex
+	cars    *MemoryCarRepository
+	reviews map[primitive.ObjectID]model.Review
+	votes   map[reviewVoteKey]bool // helpful or not
+}
+
+var _ ReviewRepository = (*MemoryReviewRepository)(nil)
+
+func NewMemoryReviewRepository(cars *MemoryCarRepository) *MemoryReviewRepository {
+	return &MemoryReviewRepository{
+		cars:    cars,
+		reviews: make(map[primitive.ObjectID]model.Review),
+		votes:   make(map[reviewVoteKey]bool),
+	}
+}
+
+func (r *MemoryReviewRepository) CreateReview(ctx context.Context, review *model.Review) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if review.ID.IsZero() {
+		review.ID = primitive.NewObjectID()
+	}
+	review.Version = 1
+	review.CreatedAt = time.Now()
+	review.UpdatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reviews[review.ID] = *review
+	r.refreshCarRating(review.CarID)
+	return nil
+}
+
+func (r *MemoryReviewRepository) GetCarReviews(ctx context.Context, carID primitive.ObjectID, params model.ReviewListParams) (*model.ReviewsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	params = normalizeReviewListParams(params)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := r.ratingStats(carID)
+
+	reviews := []model.Review{}
+	for _, review := range r.reviews {
+		if review.CarID == carID {
+			reviews = append(reviews, review)
+		}
+	}
+
+	sortReviews(reviews, params.Sort)
+
+	return &model.ReviewsResponse{
+		Reviews:            reviewPage(reviews, params),
+		AverageRating:      stats.AverageRating,
+		TotalReviews:       stats.TotalReviews,
+		RatingDistribution: stats.Distribution,
+		Page:               params.Page,
+		Limit:              params.Limit,
+		TotalPages:         (stats.TotalReviews + params.Limit - 1) / params.Limit,
+		Sort:               params.Sort,
+	}, nil
+}
+
+func (r *MemoryReviewRepository) GetReviewsByCarIDs(ctx context.Context, carIDs []primitive.ObjectID, params model.ReviewListParams) (map[primitive.ObjectID][]model.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	params = normalizeReviewListParams(params)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byCar := map[primitive.ObjectID][]model.Review{}
+	for _, review := range r.reviews {
+		if slices.Contains(carIDs, review.CarID) {
+			byCar[review.CarID] = append(byCar[review.CarID], review)
+		}
+	}
+	for carID, reviews := range byCar {
+		sortReviews(reviews, params.Sort)
+		byCar[carID] = reviewPage(reviews, params)
+	}
+	return byCar, nil
+}
+
+// sortReviews orders reviews like reviewSortOrder
+func sortReviews(reviews []model.Review, sortBy string) {
+	sort.SliceStable(reviews, func(i, j int) bool {
+		a, b := reviews[i], reviews[j]
+		switch sortBy {
+		case model.ReviewSortOldest:
+			return a.CreatedAt.Before(b.CreatedAt)
+		case model.ReviewSortHighest:
+			if a.Rating != b.Rating {
+				return a.Rating > b.Rating
+			}
+		case model.ReviewSortLowest:
+			if a.Rating != b.Rating {
+				return a.Rating < b.Rating
+			}
+		case model.ReviewSortMostHelpful:
+			if a.HelpfulScore != b.HelpfulScore {
+				return a.HelpfulScore > b.HelpfulScore
+			}
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+}
+
+// reviewPage returns the slice of sorted reviews that params selects
+func reviewPage(reviews []model.Review, params model.ReviewListParams) []model.Review {
+	start := (params.Page - 1) * params.Limit
+	if start > len(reviews) {
+		start = len(reviews)
+	}
+	end := start + params.Limit
+	if end > len(reviews) {
+		end = len(reviews)
+	}
+	return reviews[start:end]
+}
+
+func (r *MemoryReviewRepository) GetRatingStats(ctx context.Context, carID primitive.ObjectID) (*model.RatingStats, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.ratingStats(carID), nil
+}
+
+func (r *MemoryReviewRepository) UpdateReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, input model.UpdateReviewInput, version int64) (*model.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.reviews[reviewID]
+	if !ok || review.UserID != userID {
+		return nil, domain.NotFound("review")
+	}
+	if version != AnyVersion && review.Version != version {
+		return nil, domain.PreconditionFailed("review")
+	}
+
+	review.Rating = input.Rating
+	review.Comment = input.Comment
+	review.UpdatedAt = time.Now()
+	review.Version++
+	r.reviews[reviewID] = review
+
+	r.refreshCarRating(review.CarID)
+	return &review, nil
+}
+
+func (r *MemoryReviewRepository) DeleteReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.reviews[reviewID]
+	if !ok || review.UserID != userID {
+		return domain.NotFound("review")
+	}
+	if version != AnyVersion && review.Version != version {
+		return domain.PreconditionFailed("review")
+	}
+
+	delete(r.reviews, reviewID)
+	for key := range r.votes {
+		if key.reviewID == reviewID {
+			delete(r.votes, key)
+		}
+	}
+
+	r.refreshCarRating(review.CarID)
+	return nil
+}
+
+func (r *MemoryReviewRepository) GetReviewByID(ctx context.Context, reviewID primitive.ObjectID) (*model.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	review, ok := r.reviews[reviewID]
+	if !ok {
+		return nil, domain.NotFound("review")
+	}
+	return &review, nil
+}
+
+func (r *MemoryReviewRepository) VoteReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, helpful bool) (*model.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.reviews[reviewID]
+	if !ok {
+		return nil, domain.NotFound("review")
+	}
+
+	key := reviewVoteKey{reviewID: reviewID, userID: userID}
+	previous, voted := r.votes[key]
+
+	switch {
+	case !voted:
+		r.votes[key] = helpful
+		applyVote(&review, helpful, 1)
+	case previous == helpful:
+		delete(r.votes, key)
+		applyVote(&review, helpful, -1)
+	default:
+		r.votes[key] = helpful
+		applyVote(&review, previous, -1)
+		applyVote(&review, helpful, 1)
+	}
+
+	review.Version++
+	r.reviews[reviewID] = review
+	return &review, nil
+}
+
+func (r *MemoryReviewRepository) ReplyToReview(ctx context.Context, reviewID primitive.ObjectID, reply *model.ReviewReply) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	reply.CreatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.reviews[reviewID]
+	if !ok {
+		return domain.NotFound("review")
+	}
+	if review.Reply != nil {
+		return ErrReviewAlreadyReplied
+	}
+
+	stored := *reply
+	review.Reply = &stored
+	review.Version++
+	r.reviews[reviewID] = review
+	return nil
+}
+
+func (r *MemoryReviewRepository) DeleteReply(ctx context.Context, reviewID primitive.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.reviews[reviewID]
+	if !ok || review.Reply == nil {
+		return domain.NotFound("reply")
+	}
+
+	review.Reply = nil
+	review.Version++
+	r.reviews[reviewID] = review
+	return nil
+}
+
+// ratingStats mirrors MongoReviewRepository.GetRatingStats. Callers hold r.mu.
+func (r *MemoryReviewRepository) ratingStats(carID primitive.ObjectID) *model.RatingStats {
+	stats := &model.RatingStats{Distribution: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}}
+
+	var totalRating int
+	for _, review := range r.reviews {
+		if review.CarID != carID {
+			continue
+		}
+		stats.Distribution[review.Rating]++
+		stats.TotalReviews++
+		totalRating += review.Rating
+	}
+
+	if stats.TotalReviews > 0 {
+		stats.AverageRating = float64(totalRating) / float64(stats.TotalReviews)
+	}
+
+	return stats
+}
+
+// refreshCarRating updates the denormalized rating on the car. Callers hold r.mu.
+func (r *MemoryReviewRepository) refreshCarRating(carID primitive.ObjectID) {
+	if r.cars != nil {
+		r.cars.setRating(carID, r.ratingStats(carID))
+	}
+}
+
+// applyVote adds delta to the matching vote counter and the helpful score
+func applyVote(review *model.Review, helpful bool, delta int) {
+	if helpful {
+		review.HelpfulCount += delta
+		review.HelpfulScore += delta
+	} else {
+		review.UnhelpfulCount += delta
+		review.HelpfulScore -= delta
+	}
+}
@@ -0,0 +1,18 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/repository/repositorytest"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	repositorytest.TestAll(t, func(t *testing.T) repositorytest.Repos {
+		return repositorytest.NewMemoryRepos()
+	})
+}
+
+// TestMongoRepositories is skipped unless TEST_MONGO_URI is set
+func TestMongoRepositories(t *testing.T) {
+	repositorytest.TestAll(t, repositorytest.NewMongoRepos)
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryUserRepository is a thread-safe in-memory UserRepository for tests
+type MemoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]model.User
+}
+
+var _ UserRepository = (*MemoryUserRepository)(nil)
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users: make(map[primitive.ObjectID]model.User),
+	}
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *model.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	user.ID = primitive.NewObjectID()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	if user.Role == "" {
+		user.Role = "user"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.findOne(ctx, func(u model.User) bool { return u.Email == email })
+}
+
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
+	return r.findOne(ctx, func(u model.User) bool { return u.ID == id })
+}
+
+func (r *MemoryUserRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	return r.findOne(ctx, func(u model.User) bool { return u.Username == username })
+}
+
+func (r *MemoryUserRepository) Update(ctx context.Context, id primitive.ObjectID, user *model.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	user.UpdatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[id]
+	if !ok {
+		return domain.NotFound("user")
+	}
+
+	existing.Username = user.Username
+	existing.Email = user.Email
+	existing.FirstName = user.FirstName
+	existing.LastName = user.LastName
+	existing.Phone = user.Phone
+	existing.UpdatedAt = user.UpdatedAt
+
+	r.users[id] = existing
+	return nil
+}
+
+// GetUserByID is an alias for FindByID (for consistency with review handler)
+func (r *MemoryUserRepository) GetUserByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *MemoryUserRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []*model.User{}
+	for id, user := range r.users {
+		if slices.Contains(ids, id) {
+			users = append(users, &user)
+		}
+	}
+	return users, nil
+}
+
+func (r *MemoryUserRepository) findOne(ctx context.Context, match func(model.User) bool) (*model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if match(user) {
+			u := user
+			return &u, nil
+		}
+	}
+
+	return nil, domain.NotFound("user")
+}
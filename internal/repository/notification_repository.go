@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, notification *model.Notification) error
+	GetUserNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) ([]model.Notification, error)
+	MarkAsRead(ctx context.Context, notificationID, userID primitive.ObjectID) error
+}
+
+type MongoNotificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoNotificationRepository(collection *mongo.Collection) *MongoNotificationRepository {
+	return &MongoNotificationRepository{
+		collection: collection,
+	}
+}
+
+// CreateNotification stores a new unread notification
+func (r *MongoNotificationRepository) CreateNotification(ctx context.Context, notification *model.Notification) error {
+	notification.Read = false
+	notification.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, notification)
+	if err != nil {
+		return err
+	}
+
+	notification.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetUserNotifications returns a user's notifications, newest first
+func (r *MongoNotificationRepository) GetUserNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) ([]model.Notification, error) {
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	notifications := []model.Notification{}
+	if err = cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// MarkAsRead marks a notification as read (only by its recipient)
+func (r *MongoNotificationRepository) MarkAsRead(ctx context.Context, notificationID, userID primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":     notificationID,
+		"user_id": userID,
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.NotFound("notification")
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCancellation checks that every repository gives up on a request whose
+// context is already cancelled or past its deadline, returning the context's
+// error, and that an aborted write leaves nothing behind
+func TestCancellation(t *testing.T, newRepos Factory) {
+	id := primitive.NewObjectID()
+
+	calls := map[string]func(ctx context.Context, repos Repos) error{
+		"Cars.Create": func(ctx context.Context, repos Repos) error {
+			return repos.Cars.Create(ctx, &model.Car{Make: "Toyota", Model: "Camry", Year: 2020, Price: 20000})
+		},
+		"Cars.List": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Cars.List(ctx, &model.FilterParams{})
+			return err
+		},
+		"Cars.Count": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Cars.Count(ctx, &model.FilterParams{})
+			return err
+		},
+		"Cars.GetByID": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Cars.GetByID(ctx, id.Hex())
+			return err
+		},
+		"Cars.GetByIDs": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Cars.GetByIDs(ctx, []primitive.ObjectID{id})
+			return err
+		},
+		"Cars.UpsertByStockNumber": func(ctx context.Context, repos Repos) error {
+			_, _, err := repos.Cars.UpsertByStockNumber(ctx, id, "cancelled", model.UpdateCarInput{})
+			return err
+		},
+		"Users.Create": func(ctx context.Context, repos Repos) error {
+			return repos.Users.Create(ctx, &model.User{Username: "cancelled", Email: "cancelled@example.com", Role: "user"})
+		},
+		"Users.FindByID": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Users.FindByID(ctx, id)
+			return err
+		},
+		"Users.FindByIDs": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Users.FindByIDs(ctx, []primitive.ObjectID{id})
+			return err
+		},
+		"Favorites.GetUserFavorites": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Favorites.GetUserFavorites(ctx, id, model.FavoriteListParams{})
+			return err
+		},
+		"Favorites.GetUserCollections": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Favorites.GetUserCollections(ctx, id)
+			return err
+		},
+		"Favorites.FavoriteCarIDs": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Favorites.FavoriteCarIDs(ctx, id, []primitive.ObjectID{id})
+			return err
+		},
+		"Reviews.GetCarReviews": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Reviews.GetCarReviews(ctx, id, model.ReviewListParams{})
+			return err
+		},
+		"Reviews.GetReviewsByCarIDs": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Reviews.GetReviewsByCarIDs(ctx, []primitive.ObjectID{id}, model.ReviewListParams{})
+			return err
+		},
+		"Notifications.GetUserNotifications": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Notifications.GetUserNotifications(ctx, id, false)
+			return err
+		},
+		"Garage.GetUserGarage": func(ctx context.Context, repos Repos) error {
+			_, err := repos.Garage.GetUserGarage(ctx, id)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name+"/Cancelled", func(t *testing.T) {
+			repos := newRepos(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := call(ctx, repos); !errors.Is(err, context.Canceled) {
+				t.Errorf("with a cancelled context = %v, want context.Canceled", err)
+			}
+		})
+
+		t.Run(name+"/DeadlineExceeded", func(t *testing.T) {
+			repos := newRepos(t)
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer cancel()
+
+			if err := call(ctx, repos); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("past its deadline = %v, want context.DeadlineExceeded", err)
+			}
+		})
+	}
+
+	t.Run("AbortedWritesLeaveNothing", func(t *testing.T) {
+		repos := newRepos(t)
+		cancelled, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		calls["Cars.Create"](cancelled, repos)
+		calls["Users.Create"](cancelled, repos)
+
+		ctx := testContext(t)
+		cars, err := repos.Cars.List(ctx, &model.FilterParams{})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(cars) != 0 {
+			t.Errorf("List after a cancelled Create = %d cars, want 0", len(cars))
+		}
+		if _, err := repos.Users.FindByUsername(ctx, "cancelled"); err == nil {
+			t.Errorf("FindByUsername after a cancelled Create found the user")
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCarRepository checks the CarRepository contract
+func TestCarRepository(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Cars
+
+		car := &model.Car{Make: "Toyota", Model: "Camry", Year: 2020, Price: 20000}
+		if err := repo.Create(ctx, car); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if car.ID.IsZero() || car.CreatedAt.IsZero() {
+			t.Fatalf("Create did not set ID and timestamps: %+v", car)
+		}
+
+		got, err := repo.GetByID(ctx, car.ID.Hex())
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Make != "Toyota" || got.Model != "Camry" || got.Price != 20000 {
+			t.Errorf("GetByID = %+v, want the created car", got)
+		}
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Cars
+
+		if _, err := repo.GetByID(ctx, primitive.NewObjectID().Hex()); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByID of a missing car = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetByID(ctx, "not-an-id"); !errors.Is(err, domain.ErrInvalidID) {
+			t.Errorf("GetByID with an invalid ID = %v, want ErrInvalidID", err)
+		}
+		if _, err := repo.Update(ctx, primitive.NewObjectID().Hex(), model.UpdateCarInput{}, repository.AnyVersion); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("Update of a missing car = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(ctx, primitive.NewObjectID().Hex(), repository.AnyVersion); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("Delete of a missing car = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("PatchKeepsOmittedFields", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Cars
+
+		car := &model.Car{Make: "Audi", Model: "A4", Year: 2020, Price: 25000}
+		if err := repo.Create(ctx, car); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		price := 19000.0
+		patched, err := repo.Patch(ctx, car.ID.Hex(), model.PatchCarInput{Price: &price}, repository.AnyVersion)
+		if err != nil {
+			t.Fatalf("Patch: %v", err)
+		}
+		if patched.Price != 19000 || patched.Make != "Audi" || patched.Model != "A4" || patched.Year != 2020 {
+			t.Errorf("Patch returned %+v, want only the price changed", patched)
+		}
+
+		if _, err := repo.Patch(ctx, primitive.NewObjectID().Hex(), model.PatchCarInput{Price: &price}, repository.AnyVersion); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("Patch of a missing car = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("VersionChecks", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Cars
+
+		car := &model.Car{Make: "Audi", Model: "A4", Price: 25000}
+		if err := repo.Create(ctx, car); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if car.Version != 1 {
+			t.Errorf("Create set version %d, want 1", car.Version)
+		}
+
+		price := 24000.0
+		patched, err := repo.Patch(ctx, car.ID.Hex(), model.PatchCarInput{Price: &price}, car.Version)
+		if err != nil {
+			t.Fatalf("Patch with the current version: %v", err)
+		}
+		if patched.Version != car.Version+1 {
+			t.Errorf("Patch returned version %d, want %d", patched.Version, car.Version+1)
+		}
+
+		// A second writer still holding the old version loses
+		if _, err := repo.Patch(ctx, car.ID.Hex(), model.PatchCarInput{Price: &price}, car.Version); !errors.Is(err, domain.ErrPreconditionFailed) {
+			t.Errorf("Patch with a stale version = %v, want ErrPreconditionFailed", err)
+		}
+		if err := repo.Delete(ctx, car.ID.Hex(), car.Version); !errors.Is(err, domain.ErrPreconditionFailed) {
+			t.Errorf("Delete with a stale version = %v, want ErrPreconditionFailed", err)
+		}
+		if err := repo.Delete(ctx, car.ID.Hex(), patched.Version); err != nil {
+			t.Errorf("Delete with the current version: %v", err)
+		}
+	})
+
+	t.Run("ListFiltersAndSorts", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Cars
+
+		for _, c := range []model.Car{
+			{Make: "BMW", BodyType: "sedan", Year: 2018, Price: 30000},
+			{Make: "BMW", BodyType: "suv", Year: 2022, Price: 50000},
+			{Make: "Kia", BodyType: "sedan", Year: 2021, Price: 15000},
+		} {
+			car := c
+			if err := repo.Create(ctx, &car); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		bmw := "BMW"
+		cars, err := repo.List(ctx, &model.FilterParams{Make: &bmw})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(cars) != 2 {
+			t.Errorf("List(make=BMW) returned %d cars, want 2", len(cars))
+		}
+
+		minPrice, maxYear := 20000.0, 2020
+		cars, err = repo.List(ctx, &model.FilterParams{MinPrice: &minPrice, MaxYear: &maxYear})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(cars) != 1 || cars[0].Price != 30000 {
+			t.Errorf("List(min_price=20000, max_year=2020) = %v, want the 2018 BMW", cars)
+		}
+
+		cars, err = repo.List(ctx, &model.FilterParams{Sort: model.CarSortPriceAsc})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(cars) != 3 || cars[0].Price != 15000 || cars[2].Price != 50000 {
+			t.Errorf("List(sort=price_asc) returned cars out of order")
+		}
+
+		cars, err = repo.List(ctx, &model.FilterParams{Sort: model.CarSortPriceAsc, Page: 2, Limit: 2})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(cars) != 1 || cars[0].Price != 50000 {
+			t.Errorf("List(sort=price_asc, page=2, limit=2) = %v, want the last car", cars)
+		}
+
+		count, err := repo.Count(ctx, &model.FilterParams{Make: &bmw, Page: 2, Limit: 1})
+		if err != nil {
+			t.Fatalf("Count: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("Count(make=BMW) = %d, want 2", count)
+		}
+	})
+
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Cars
+
+		car := &model.Car{Make: "Audi", Model: "A4", Price: 25000}
+		if err := repo.Create(ctx, car); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		updated, err := repo.Update(ctx, car.ID.Hex(), model.UpdateCarInput{Make: "Audi", Model: "A6", Price: 35000}, repository.AnyVersion)
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if updated.Model != "A6" || updated.Price != 35000 {
+			t.Errorf("Update returned %+v", updated)
+		}
+
+		got, err := repo.GetByID(ctx, car.ID.Hex())
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Model != "A6" || got.Price != 35000 {
+			t.Errorf("after Update got %+v", got)
+		}
+
+		if err := repo.Delete(ctx, car.ID.Hex(), repository.AnyVersion); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, car.ID.Hex()); err == nil {
+			t.Error("GetByID after Delete returned no error")
+		}
+	})
+
+	t.Run("GetByIDs", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		first, second := createCar(t, repos, 10000), createCar(t, repos, 20000)
+		createCar(t, repos, 30000)
+
+		cars, err := repos.Cars.GetByIDs(ctx, []primitive.ObjectID{first.ID, second.ID, first.ID, primitive.NewObjectID()})
+		if err != nil {
+			t.Fatalf("GetByIDs: %v", err)
+		}
+		var total float64
+		for _, car := range cars {
+			total += car.Price
+		}
+		if len(cars) != 2 || total != 30000 {
+			t.Errorf("GetByIDs returned %d cars worth %v, want the 2 requested", len(cars), total)
+		}
+	})
+
+	t.Run("UpsertByStockNumber", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Cars
+		dealer, other := primitive.NewObjectID(), primitive.NewObjectID()
+
+		input := model.UpdateCarInput{Make: "Toyota", Model: "Corolla", Year: 2019, Price: 15000}
+		created, isNew, err := repo.UpsertByStockNumber(ctx, dealer, "A-1", input)
+		if err != nil {
+			t.Fatalf("UpsertByStockNumber: %v", err)
+		}
+		if !isNew || created.ID.IsZero() || created.Version != 1 || created.DealerID != dealer || created.StockNumber != "A-1" {
+			t.Fatalf("first upsert = %+v created %v, want a new car of the dealer", created, isNew)
+		}
+
+		input.Price = 14000
+		updated, isNew, err := repo.UpsertByStockNumber(ctx, dealer, "A-1", input)
+		if err != nil {
+			t.Fatalf("UpsertByStockNumber: %v", err)
+		}
+		if isNew || updated.ID != created.ID || updated.Price != 14000 || updated.Version != 2 || !updated.CreatedAt.Equal(created.CreatedAt) {
+			t.Errorf("second upsert = %+v created %v, want the same car updated", updated, isNew)
+		}
+
+		// Stock numbers are per dealer
+		if _, isNew, err := repo.UpsertByStockNumber(ctx, other, "A-1", input); err != nil || !isNew {
+			t.Errorf("upsert of another dealer's stock number: created %v err %v, want a new car", isNew, err)
+		}
+
+		duplicate := &model.Car{Make: "Toyota", Model: "Corolla", DealerID: dealer, StockNumber: "A-1"}
+		if err := repo.Create(ctx, duplicate); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("Create with a stock number in use = %v, want ErrConflict", err)
+		}
+
+		cars, err := repo.List(ctx, &model.FilterParams{DealerID: &dealer})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(cars) != 1 || cars[0].ID != created.ID {
+			t.Errorf("List of the dealer's cars = %d cars, want the upserted one", len(cars))
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestFavoriteRepository checks the FavoriteRepository contract
+func TestFavoriteRepository(t *testing.T, newRepos Factory) {
+	t.Run("AddListRemove", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		userID := primitive.NewObjectID()
+		cheap, pricey := createCar(t, repos, 10000), createCar(t, repos, 90000)
+
+		for _, car := range []*model.Car{cheap, pricey, cheap} { // adding twice is a no-op
+			if err := repos.Favorites.AddToFavorites(ctx, &model.Favorite{UserID: userID, CarID: car.ID}); err != nil {
+				t.Fatalf("AddToFavorites: %v", err)
+			}
+		}
+
+		count, err := repos.Favorites.GetFavoritesCount(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetFavoritesCount: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("GetFavoritesCount = %d, want 2", count)
+		}
+
+		page, err := repos.Favorites.GetUserFavorites(ctx, userID, model.FavoriteListParams{Sort: model.FavoriteSortPriceDesc})
+		if err != nil {
+			t.Fatalf("GetUserFavorites: %v", err)
+		}
+		if page.Total != 2 || len(page.Favorites) != 2 || page.Favorites[0].Car.Price != 90000 {
+			t.Errorf("GetUserFavorites(sort=price_desc) = %+v", page)
+		}
+
+		page, err = repos.Favorites.GetUserFavorites(ctx, userID, model.FavoriteListParams{Page: 2, Limit: 1, Sort: model.FavoriteSortPriceAsc})
+		if err != nil {
+			t.Fatalf("GetUserFavorites: %v", err)
+		}
+		if len(page.Favorites) != 1 || page.Favorites[0].CarID != pricey.ID || page.TotalPages != 2 {
+			t.Errorf("GetUserFavorites(page=2, limit=1) = %+v", page)
+		}
+
+		if err := repos.Favorites.RemoveFromFavorites(ctx, userID, cheap.ID); err != nil {
+			t.Fatalf("RemoveFromFavorites: %v", err)
+		}
+		ok, err := repos.Favorites.IsFavorite(ctx, userID, cheap.ID)
+		if err != nil {
+			t.Fatalf("IsFavorite: %v", err)
+		}
+		if ok {
+			t.Error("IsFavorite after RemoveFromFavorites = true")
+		}
+	})
+
+	t.Run("SkipsDeletedCars", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		userID := primitive.NewObjectID()
+		kept, deleted := createCar(t, repos, 10000), createCar(t, repos, 20000)
+
+		for _, car := range []*model.Car{kept, deleted} {
+			if err := repos.Favorites.AddToFavorites(ctx, &model.Favorite{UserID: userID, CarID: car.ID}); err != nil {
+				t.Fatalf("AddToFavorites: %v", err)
+			}
+		}
+		if err := repos.Cars.Delete(ctx, deleted.ID.Hex(), repository.AnyVersion); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		// Date sorts page before joining the cars, price sorts after
+		for _, sort := range []string{model.FavoriteSortNewest, model.FavoriteSortOldest, model.FavoriteSortPriceAsc} {
+			page, err := repos.Favorites.GetUserFavorites(ctx, userID, model.FavoriteListParams{Limit: 1, Sort: sort})
+			if err != nil {
+				t.Fatalf("GetUserFavorites sorted by %s: %v", sort, err)
+			}
+			if len(page.Favorites) != 1 || page.Favorites[0].CarID != kept.ID || page.Total != 1 || page.TotalPages != 1 {
+				t.Errorf("GetUserFavorites sorted by %s = %d favorites of %d in %d pages, want only the car that was not deleted", sort, len(page.Favorites), page.Total, page.TotalPages)
+			}
+		}
+	})
+
+	t.Run("CollectionsNotesAndSharing", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		userID, otherID := primitive.NewObjectID(), primitive.NewObjectID()
+		car := createCar(t, repos, 30000)
+
+		collection := &model.FavoriteCollection{UserID: userID, Name: "Family SUVs"}
+		if err := repos.Favorites.CreateCollection(ctx, collection); err != nil {
+			t.Fatalf("CreateCollection: %v", err)
+		}
+		if err := repos.Favorites.AddToFavorites(ctx, &model.Favorite{UserID: userID, CarID: car.ID}); err != nil {
+			t.Fatalf("AddToFavorites: %v", err)
+		}
+
+		// Other users cannot see or use the collection
+		if _, err := repos.Favorites.GetCollection(ctx, collection.ID, otherID); !errors.Is(err, repository.ErrCollectionNotFound) {
+			t.Errorf("GetCollection by another user = %v, want ErrCollectionNotFound", err)
+		}
+
+		if err := repos.Favorites.MoveFavorite(ctx, userID, car.ID, collection.ID); err != nil {
+			t.Fatalf("MoveFavorite: %v", err)
+		}
+		if err := repos.Favorites.UpdateFavoriteNote(ctx, userID, car.ID, "test drive on Friday"); err != nil {
+			t.Fatalf("UpdateFavoriteNote: %v", err)
+		}
+
+		page, err := repos.Favorites.GetCollectionFavorites(ctx, userID, collection.ID, model.FavoriteListParams{})
+		if err != nil {
+			t.Fatalf("GetCollectionFavorites: %v", err)
+		}
+		if len(page.Favorites) != 1 || page.Favorites[0].Note != "test drive on Friday" {
+			t.Errorf("GetCollectionFavorites = %+v", page.Favorites)
+		}
+
+		token, err := repos.Favorites.ShareCollection(ctx, collection.ID, userID)
+		if err != nil || token == "" {
+			t.Fatalf("ShareCollection = %q, %v", token, err)
+		}
+		shared, err := repos.Favorites.GetSharedCollection(ctx, token)
+		if err != nil {
+			t.Fatalf("GetSharedCollection: %v", err)
+		}
+		if shared.Name != "Family SUVs" || len(shared.Cars) != 1 {
+			t.Errorf("GetSharedCollection = %+v", shared)
+		}
+
+		if err := repos.Favorites.UnshareCollection(ctx, collection.ID, userID); err != nil {
+			t.Fatalf("UnshareCollection: %v", err)
+		}
+		if _, err := repos.Favorites.GetSharedCollection(ctx, token); !errors.Is(err, repository.ErrCollectionNotFound) {
+			t.Errorf("GetSharedCollection after unshare = %v, want ErrCollectionNotFound", err)
+		}
+
+		// Deleting the collection keeps the favorite
+		if err := repos.Favorites.DeleteCollection(ctx, collection.ID, userID); err != nil {
+			t.Fatalf("DeleteCollection: %v", err)
+		}
+		ok, err := repos.Favorites.IsFavorite(ctx, userID, car.ID)
+		if err != nil || !ok {
+			t.Errorf("IsFavorite after DeleteCollection = %v, %v; want true", ok, err)
+		}
+	})
+
+	t.Run("FavoriteCarIDs", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		userID := primitive.NewObjectID()
+		liked, other := createCar(t, repos, 10000), createCar(t, repos, 20000)
+
+		for _, fav := range []*model.Favorite{{UserID: userID, CarID: liked.ID}, {UserID: primitive.NewObjectID(), CarID: other.ID}} {
+			if err := repos.Favorites.AddToFavorites(ctx, fav); err != nil {
+				t.Fatalf("AddToFavorites: %v", err)
+			}
+		}
+
+		ids, err := repos.Favorites.FavoriteCarIDs(ctx, userID, []primitive.ObjectID{liked.ID, other.ID})
+		if err != nil {
+			t.Fatalf("FavoriteCarIDs: %v", err)
+		}
+		if len(ids) != 1 || ids[0] != liked.ID {
+			t.Errorf("FavoriteCarIDs = %v, want only %s", ids, liked.ID.Hex())
+		}
+	})
+}
+
+func createCar(t *testing.T, repos Repos, price float64) *model.Car {
+	t.Helper()
+
+	car := &model.Car{Make: "Toyota", Model: "RAV4", Year: 2021, Price: price}
+	if err := repos.Cars.Create(testContext(t), car); err != nil {
+		t.Fatalf("Create car: %v", err)
+	}
+	return car
+}
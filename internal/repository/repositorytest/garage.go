@@ -0,0 +1,71 @@
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestGarageRepository checks the GarageRepository contract
+func TestGarageRepository(t *testing.T, newRepos Factory) {
+	ctx := testContext(t)
+	repos := newRepos(t)
+	userID := primitive.NewObjectID()
+	kept, sold := createCar(t, repos, 20000), createCar(t, repos, 30000)
+
+	item := &model.GarageItem{UserID: userID, CarID: kept.ID, PriceWhenAdded: kept.Price}
+	if err := repos.Garage.AddToGarage(ctx, item); err != nil {
+		t.Fatalf("AddToGarage: %v", err)
+	}
+	if item.ID.IsZero() {
+		t.Error("AddToGarage did not set ID")
+	}
+
+	// Import merges: the car already in the garage and unknown cars are skipped
+	imported, err := repos.Garage.ImportGarage(ctx, userID, []model.GarageItem{
+		{CarID: kept.ID, PriceWhenAdded: 1},
+		{CarID: sold.ID, PriceWhenAdded: 35000},
+		{CarID: primitive.NewObjectID(), PriceWhenAdded: 1},
+	})
+	if err != nil {
+		t.Fatalf("ImportGarage: %v", err)
+	}
+	if imported != 1 {
+		t.Errorf("ImportGarage imported %d cars, want 1", imported)
+	}
+
+	if err := repos.Cars.Delete(ctx, sold.ID.Hex(), repository.AnyVersion); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	garage, err := repos.Garage.GetUserGarage(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetUserGarage: %v", err)
+	}
+	if len(garage) != 2 {
+		t.Fatalf("GetUserGarage returned %d items, want 2", len(garage))
+	}
+	for _, entry := range garage {
+		switch entry.CarID {
+		case kept.ID:
+			if entry.Sold || entry.PriceChanged || entry.Car == nil {
+				t.Errorf("unchanged car flagged: %+v", entry)
+			}
+		case sold.ID:
+			if !entry.Sold || entry.Car != nil {
+				t.Errorf("deleted car not flagged as sold: %+v", entry)
+			}
+		}
+	}
+
+	if err := repos.Garage.RemoveFromGarage(ctx, userID, kept.ID); err != nil {
+		t.Fatalf("RemoveFromGarage: %v", err)
+	}
+	if err := repos.Garage.RemoveFromGarage(ctx, userID, kept.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("second RemoveFromGarage = %v, want ErrNotFound", err)
+	}
+}
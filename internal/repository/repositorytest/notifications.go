@@ -0,0 +1,46 @@
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestNotificationRepository checks the NotificationRepository contract
+func TestNotificationRepository(t *testing.T, newRepos Factory) {
+	ctx := testContext(t)
+	repo := newRepos(t).Notifications
+
+	userID := primitive.NewObjectID()
+	n := &model.Notification{UserID: userID, Type: model.NotificationReviewReply, Message: "hello"}
+	if err := repo.CreateNotification(ctx, n); err != nil {
+		t.Fatalf("CreateNotification: %v", err)
+	}
+
+	unread, err := repo.GetUserNotifications(ctx, userID, true)
+	if err != nil {
+		t.Fatalf("GetUserNotifications: %v", err)
+	}
+	if len(unread) != 1 {
+		t.Fatalf("got %d unread notifications, want 1", len(unread))
+	}
+
+	// Only the recipient may mark it read
+	if err := repo.MarkAsRead(ctx, n.ID, primitive.NewObjectID()); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("MarkAsRead by another user = %v, want ErrNotFound", err)
+	}
+	if err := repo.MarkAsRead(ctx, n.ID, userID); err != nil {
+		t.Fatalf("MarkAsRead: %v", err)
+	}
+
+	unread, err = repo.GetUserNotifications(ctx, userID, true)
+	if err != nil {
+		t.Fatalf("GetUserNotifications: %v", err)
+	}
+	if len(unread) != 0 {
+		t.Errorf("got %d unread notifications after MarkAsRead, want 0", len(unread))
+	}
+}
@@ -0,0 +1,125 @@
+// Package repositorytest is a contract test suite for the repository interfaces.
+// The same checks run against the in-memory and the Mongo implementations so
+// handler tests can rely on the in-memory ones behaving like production:
+//
+//	func TestMemoryCarRepository(t *testing.T) {
+//		repositorytest.TestCarRepository(t, func(t *testing.T) repositorytest.Repos {
+//			return repositorytest.NewMemoryRepos()
+//		})
+//	}
+//
+//	func TestMongoCarRepository(t *testing.T) {
+//		repositorytest.TestCarRepository(t, repositorytest.NewMongoRepos)
+//	}
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/migrate"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoURIEnv names the environment variable with the MongoDB used by NewMongoRepos
+const MongoURIEnv = "TEST_MONGO_URI"
+
+// Repos is one set of repositories sharing the same storage
+type Repos struct {
+	Cars          repository.CarRepository
+	Users         repository.UserRepository
+	Favorites     repository.FavoriteRepository
+	Reviews       repository.ReviewRepository
+	Notifications repository.NotificationRepository
+	Garage        repository.GarageRepository
+}
+
+// Factory returns a fresh, empty set of repositories for one test
+type Factory func(t *testing.T) Repos
+
+// NewMemoryRepos returns in-memory repositories wired to the same car store
+func NewMemoryRepos() Repos {
+	cars := repository.NewMemoryCarRepository()
+	return Repos{
+		Cars:          cars,
+		Users:         repository.NewMemoryUserRepository(),
+		Favorites:     repository.NewMemoryFavoriteRepository(cars),
+		Reviews:       repository.NewMemoryReviewRepository(cars),
+		Notifications: repository.NewMemoryNotificationRepository(),
+		Garage:        repository.NewMemoryGarageRepository(cars),
+	}
+}
+
+// NewMongoRepos returns Mongo repositories backed by a throwaway database that is
+// dropped when the test ends. The test is skipped unless TEST_MONGO_URI is set.
+func NewMongoRepos(t *testing.T) Repos {
+	t.Helper()
+
+	db := NewMongoDatabase(t)
+	cars := db.Collection("cars")
+	favorites := repository.NewMongoFavoriteRepository(db.Collection("favorites"), cars, db.Collection("favorite_collections"))
+
+	return Repos{
+		Cars:          repository.NewMongoCarRepository(cars),
+		Users:         repository.NewMongoUserRepository(db.Collection("users")),
+		Favorites:     favorites,
+		Reviews:       repository.NewMongoReviewRepository(db.Collection("reviews"), cars, db.Collection("review_votes")),
+		Notifications: repository.NewMongoNotificationRepository(db.Collection("notifications")),
+		Garage:        repository.NewMongoGarageRepository(db.Collection("garage"), cars),
+	}
+}
+
+// NewMongoDatabase returns a migrated throwaway database that is dropped when the
+// test or benchmark ends. It is skipped unless TEST_MONGO_URI is set.
+func NewMongoDatabase(tb testing.TB) *mongo.Database {
+	tb.Helper()
+
+	uri := os.Getenv(MongoURIEnv)
+	if uri == "" {
+		tb.Skipf("%s is not set", MongoURIEnv)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		tb.Fatalf("connect to MongoDB: %v", err)
+	}
+
+	db := client.Database(fmt.Sprintf("car_store_test_%s", primitive.NewObjectID().Hex()))
+	tb.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	if _, err := migrate.NewRunner(db, migrate.All).Up(ctx, 0); err != nil {
+		tb.Fatalf("migrate test database: %v", err)
+	}
+	return db
+}
+
+// TestAll runs every contract against the repositories made by newRepos
+func TestAll(t *testing.T, newRepos Factory) {
+	t.Run("Cars", func(t *testing.T) { TestCarRepository(t, newRepos) })
+	t.Run("Users", func(t *testing.T) { TestUserRepository(t, newRepos) })
+	t.Run("Favorites", func(t *testing.T) { TestFavoriteRepository(t, newRepos) })
+	t.Run("Reviews", func(t *testing.T) { TestReviewRepository(t, newRepos) })
+	t.Run("Notifications", func(t *testing.T) { TestNotificationRepository(t, newRepos) })
+	t.Run("Garage", func(t *testing.T) { TestGarageRepository(t, newRepos) })
+	t.Run("Cancellation", func(t *testing.T) { TestCancellation(t, newRepos) })
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
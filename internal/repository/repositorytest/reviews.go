@@ -0,0 +1,181 @@
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestReviewRepository checks the ReviewRepository contract
+func TestReviewRepository(t *testing.T, newRepos Factory) {
+	t.Run("StatsPagingAndCarRating", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		car := createCar(t, repos, 20000)
+
+		for _, rating := range []int{5, 4, 4, 1} {
+			review := &model.Review{CarID: car.ID, UserID: primitive.NewObjectID(), Rating: rating}
+			if err := repos.Reviews.CreateReview(ctx, review); err != nil {
+				t.Fatalf("CreateReview: %v", err)
+			}
+		}
+
+		resp, err := repos.Reviews.GetCarReviews(ctx, car.ID, model.ReviewListParams{Limit: 3, Sort: model.ReviewSortLowest})
+		if err != nil {
+			t.Fatalf("GetCarReviews: %v", err)
+		}
+		if resp.TotalReviews != 4 || resp.AverageRating != 3.5 {
+			t.Errorf("GetCarReviews total=%d avg=%v, want 4 and 3.5", resp.TotalReviews, resp.AverageRating)
+		}
+		if resp.RatingDistribution[4] != 2 || resp.RatingDistribution[2] != 0 {
+			t.Errorf("RatingDistribution = %v", resp.RatingDistribution)
+		}
+		if len(resp.Reviews) != 3 || resp.Reviews[0].Rating != 1 || resp.TotalPages != 2 {
+			t.Errorf("GetCarReviews(limit=3, sort=lowest) returned %d reviews, first rating %d", len(resp.Reviews), resp.Reviews[0].Rating)
+		}
+
+		got, err := repos.Cars.GetByID(ctx, car.ID.Hex())
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.RatingAvg != 3.5 || got.RatingCount != 4 {
+			t.Errorf("car rating = %v (%d), want 3.5 (4)", got.RatingAvg, got.RatingCount)
+		}
+	})
+
+	t.Run("OwnershipChecks", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		car := createCar(t, repos, 20000)
+		ownerID := primitive.NewObjectID()
+
+		review := &model.Review{CarID: car.ID, UserID: ownerID, Rating: 3}
+		if err := repos.Reviews.CreateReview(ctx, review); err != nil {
+			t.Fatalf("CreateReview: %v", err)
+		}
+
+		input := model.UpdateReviewInput{Rating: 5, Comment: "changed"}
+		if _, err := repos.Reviews.UpdateReview(ctx, review.ID, primitive.NewObjectID(), input, repository.AnyVersion); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("UpdateReview by another user = %v, want ErrNotFound", err)
+		}
+		if err := repos.Reviews.DeleteReview(ctx, review.ID, primitive.NewObjectID(), repository.AnyVersion); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("DeleteReview by another user = %v, want ErrNotFound", err)
+		}
+
+		if _, err := repos.Reviews.UpdateReview(ctx, review.ID, ownerID, input, review.Version+1); !errors.Is(err, domain.ErrPreconditionFailed) {
+			t.Errorf("UpdateReview with a wrong version = %v, want ErrPreconditionFailed", err)
+		}
+		if _, err := repos.Reviews.UpdateReview(ctx, review.ID, ownerID, input, review.Version); err != nil {
+			t.Fatalf("UpdateReview: %v", err)
+		}
+		got, err := repos.Reviews.GetReviewByID(ctx, review.ID)
+		if err != nil {
+			t.Fatalf("GetReviewByID: %v", err)
+		}
+		if got.Rating != 5 || got.Comment != "changed" {
+			t.Errorf("after UpdateReview got %+v", got)
+		}
+
+		if err := repos.Reviews.DeleteReview(ctx, review.ID, ownerID, repository.AnyVersion); err != nil {
+			t.Fatalf("DeleteReview: %v", err)
+		}
+		if _, err := repos.Reviews.GetReviewByID(ctx, review.ID); err == nil {
+			t.Error("GetReviewByID after DeleteReview returned no error")
+		}
+	})
+
+	t.Run("VotesAndReplies", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		car := createCar(t, repos, 20000)
+		voterID := primitive.NewObjectID()
+
+		review := &model.Review{CarID: car.ID, UserID: primitive.NewObjectID(), Rating: 4}
+		if err := repos.Reviews.CreateReview(ctx, review); err != nil {
+			t.Fatalf("CreateReview: %v", err)
+		}
+
+		got, err := repos.Reviews.VoteReview(ctx, review.ID, voterID, true)
+		if err != nil {
+			t.Fatalf("VoteReview: %v", err)
+		}
+		if got.HelpfulCount != 1 || got.HelpfulScore != 1 {
+			t.Errorf("after helpful vote got helpful=%d score=%d", got.HelpfulCount, got.HelpfulScore)
+		}
+
+		// Switching the vote moves it to the other counter
+		got, err = repos.Reviews.VoteReview(ctx, review.ID, voterID, false)
+		if err != nil {
+			t.Fatalf("VoteReview: %v", err)
+		}
+		if got.HelpfulCount != 0 || got.UnhelpfulCount != 1 || got.HelpfulScore != -1 {
+			t.Errorf("after switching vote got %+v", got)
+		}
+
+		// Repeating the vote removes it
+		got, err = repos.Reviews.VoteReview(ctx, review.ID, voterID, false)
+		if err != nil {
+			t.Fatalf("VoteReview: %v", err)
+		}
+		if got.UnhelpfulCount != 0 || got.HelpfulScore != 0 {
+			t.Errorf("after toggling vote off got %+v", got)
+		}
+
+		reply := &model.ReviewReply{UserID: primitive.NewObjectID(), Username: "dealer", Comment: "Thanks!"}
+		if err := repos.Reviews.ReplyToReview(ctx, review.ID, reply); err != nil {
+			t.Fatalf("ReplyToReview: %v", err)
+		}
+		if err := repos.Reviews.ReplyToReview(ctx, review.ID, reply); !errors.Is(err, repository.ErrReviewAlreadyReplied) {
+			t.Errorf("second ReplyToReview = %v, want ErrReviewAlreadyReplied", err)
+		}
+
+		resp, err := repos.Reviews.GetCarReviews(ctx, car.ID, model.ReviewListParams{})
+		if err != nil {
+			t.Fatalf("GetCarReviews: %v", err)
+		}
+		if len(resp.Reviews) != 1 || resp.Reviews[0].Reply == nil || resp.Reviews[0].Reply.Comment != "Thanks!" {
+			t.Errorf("GetCarReviews did not return the nested reply")
+		}
+
+		if err := repos.Reviews.DeleteReply(ctx, review.ID); err != nil {
+			t.Fatalf("DeleteReply: %v", err)
+		}
+		if err := repos.Reviews.DeleteReply(ctx, review.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("second DeleteReply = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("GetReviewsByCarIDs", func(t *testing.T) {
+		ctx := testContext(t)
+		repos := newRepos(t)
+		busy, quiet, empty := createCar(t, repos, 10000), createCar(t, repos, 20000), createCar(t, repos, 30000)
+
+		for _, r := range []struct {
+			car    *model.Car
+			rating int
+		}{{busy, 2}, {busy, 5}, {busy, 3}, {quiet, 4}} {
+			review := &model.Review{CarID: r.car.ID, UserID: primitive.NewObjectID(), Rating: r.rating}
+			if err := repos.Reviews.CreateReview(ctx, review); err != nil {
+				t.Fatalf("CreateReview: %v", err)
+			}
+		}
+
+		byCar, err := repos.Reviews.GetReviewsByCarIDs(ctx, []primitive.ObjectID{busy.ID, quiet.ID, empty.ID}, model.ReviewListParams{Limit: 2, Sort: model.ReviewSortHighest})
+		if err != nil {
+			t.Fatalf("GetReviewsByCarIDs: %v", err)
+		}
+		if got := byCar[busy.ID]; len(got) != 2 || got[0].Rating != 5 || got[1].Rating != 3 {
+			t.Errorf("GetReviewsByCarIDs(limit=2, sort=highest) for the busy car = %+v, want ratings 5 and 3", got)
+		}
+		if got := byCar[quiet.ID]; len(got) != 1 || got[0].Rating != 4 {
+			t.Errorf("GetReviewsByCarIDs for the quiet car = %+v, want its one review", got)
+		}
+		if got := byCar[empty.ID]; len(got) != 0 {
+			t.Errorf("GetReviewsByCarIDs for a car without reviews = %+v", got)
+		}
+	})
+}
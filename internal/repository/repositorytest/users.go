@@ -0,0 +1,110 @@
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestUserRepository checks the UserRepository contract
+func TestUserRepository(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Users
+
+		user := &model.User{Username: "serik", Email: "serik@example.com", Password: "hash"}
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if user.ID.IsZero() {
+			t.Fatal("Create did not set ID")
+		}
+		if user.Role != "user" {
+			t.Errorf("Create set role %q, want the default %q", user.Role, "user")
+		}
+
+		for name, find := range map[string]func() (*model.User, error){
+			"FindByEmail":    func() (*model.User, error) { return repo.FindByEmail(ctx, "serik@example.com") },
+			"FindByUsername": func() (*model.User, error) { return repo.FindByUsername(ctx, "serik") },
+			"FindByID":       func() (*model.User, error) { return repo.FindByID(ctx, user.ID) },
+			"GetUserByID":    func() (*model.User, error) { return repo.GetUserByID(ctx, user.ID) },
+		} {
+			got, err := find()
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			if got.ID != user.ID {
+				t.Errorf("%s returned user %s, want %s", name, got.ID.Hex(), user.ID.Hex())
+			}
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Users
+
+		if _, err := repo.FindByEmail(ctx, "nobody@example.com"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("FindByEmail of a missing user = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.FindByID(ctx, primitive.NewObjectID()); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("FindByID of a missing user = %v, want ErrNotFound", err)
+		}
+		if err := repo.Update(ctx, primitive.NewObjectID(), &model.User{}); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("Update of a missing user = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Users
+
+		user := &model.User{Username: "dimash", Email: "dimash@example.com"}
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		user.FirstName = "Dimash"
+		if err := repo.Update(ctx, user.ID, user); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		got, err := repo.FindByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if got.FirstName != "Dimash" {
+			t.Errorf("after Update first name = %q, want %q", got.FirstName, "Dimash")
+		}
+	})
+
+	t.Run("FindByIDs", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := newRepos(t).Users
+
+		var ids []primitive.ObjectID
+		for _, name := range []string{"aruzhan", "timur", "madina"} {
+			user := &model.User{Username: name, Email: name + "@example.com"}
+			if err := repo.Create(ctx, user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			ids = append(ids, user.ID)
+		}
+
+		users, err := repo.FindByIDs(ctx, []primitive.ObjectID{ids[0], ids[2], ids[2], primitive.NewObjectID()})
+		if err != nil {
+			t.Fatalf("FindByIDs: %v", err)
+		}
+		if len(users) != 2 {
+			t.Fatalf("FindByIDs returned %d users, want 2", len(users))
+		}
+		for _, user := range users {
+			if user.ID != ids[0] && user.ID != ids[2] {
+				t.Errorf("FindByIDs returned %s, which was not requested", user.Username)
+			}
+		}
+	})
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
+	"github.com/teamserik/online-car-store/internal/domain"
 	"github.com/teamserik/online-car-store/internal/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,24 +15,44 @@ import (
 
 type ReviewRepository interface {
 	CreateReview(ctx context.Context, review *model.Review) error
-	GetCarReviews(ctx context.Context, carID primitive.ObjectID) (*model.ReviewsResponse, error)
-	UpdateReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, input model.UpdateReviewInput) error
-	DeleteReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID) error
+	GetCarReviews(ctx context.Context, carID primitive.ObjectID, params model.ReviewListParams) (*model.ReviewsResponse, error)
+	// GetReviewsByCarIDs returns the same page of reviews for each of several cars, keyed by car
+	GetReviewsByCarIDs(ctx context.Context, carIDs []primitive.ObjectID, params model.ReviewListParams) (map[primitive.ObjectID][]model.Review, error)
+	GetRatingStats(ctx context.Context, carID primitive.ObjectID) (*model.RatingStats, error)
+	// UpdateReview and DeleteReview check the version like CarRepository.Update
+	UpdateReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, input model.UpdateReviewInput, version int64) (*model.Review, error)
+	DeleteReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, version int64) error
 	GetReviewByID(ctx context.Context, reviewID primitive.ObjectID) (*model.Review, error)
+	VoteReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, helpful bool) (*model.Review, error)
+	ReplyToReview(ctx context.Context, reviewID primitive.ObjectID, reply *model.ReviewReply) error
+	DeleteReply(ctx context.Context, reviewID primitive.ObjectID) error
 }
 
+// ErrReviewAlreadyReplied is returned when a review already has a reply
+var ErrReviewAlreadyReplied = domain.Conflict("review already has a reply")
+
+const (
+	defaultReviewsLimit = 10
+	maxReviewsLimit     = 100
+)
+
 type MongoReviewRepository struct {
-	collection *mongo.Collection
+	collection      *mongo.Collection
+	carsCollection  *mongo.Collection
+	votesCollection *mongo.Collection
 }
 
-func NewMongoReviewRepository(collection *mongo.Collection) *MongoReviewRepository {
+func NewMongoReviewRepository(collection *mongo.Collection, carsCollection *mongo.Collection, votesCollection *mongo.Collection) *MongoReviewRepository {
 	return &MongoReviewRepository{
-		collection: collection,
+		collection:      collection,
+		carsCollection:  carsCollection,
+		votesCollection: votesCollection,
 	}
 }
 
 // CreateReview creates a new review
 func (r *MongoReviewRepository) CreateReview(ctx context.Context, review *model.Review) error {
+	review.Version = 1
 	review.CreatedAt = time.Now()
 	review.UpdatedAt = time.Now()
 
@@ -40,51 +62,131 @@ func (r *MongoReviewRepository) CreateReview(ctx context.Context, review *model.
 	}
 
 	review.ID = result.InsertedID.(primitive.ObjectID)
-	return nil
+	return r.refreshCarRating(ctx, review.CarID)
 }
 
-// GetCarReviews returns all reviews for a car with aggregated data
-func (r *MongoReviewRepository) GetCarReviews(ctx context.Context, carID primitive.ObjectID) (*model.ReviewsResponse, error) {
-	filter := bson.M{"car_id": carID}
+// GetCarReviews returns a page of reviews for a car with aggregated data
+func (r *MongoReviewRepository) GetCarReviews(ctx context.Context, carID primitive.ObjectID, params model.ReviewListParams) (*model.ReviewsResponse, error) {
+	params = normalizeReviewListParams(params)
+
+	stats, err := r.GetRatingStats(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Sort by created_at descending (newest first)
-	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	opts := options.Find().
+		SetSort(reviewSortOrder(params.Sort)).
+		SetSkip(int64((params.Page - 1) * params.Limit)).
+		SetLimit(int64(params.Limit))
 
-	cursor, err := r.collection.Find(ctx, filter, opts)
+	cursor, err := r.collection.Find(ctx, bson.M{"car_id": carID}, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var reviews []model.Review
+	reviews := []model.Review{}
 	if err = cursor.All(ctx, &reviews); err != nil {
 		return nil, err
 	}
 
-	// Calculate average rating
+	return &model.ReviewsResponse{
+		Reviews:            reviews,
+		AverageRating:      stats.AverageRating,
+		TotalReviews:       stats.TotalReviews,
+		RatingDistribution: stats.Distribution,
+		Page:               params.Page,
+		Limit:              params.Limit,
+		TotalPages:         (stats.TotalReviews + params.Limit - 1) / params.Limit,
+		Sort:               params.Sort,
+	}, nil
+}
+
+// GetReviewsByCarIDs pages each car's reviews in one aggregation. $topN keeps
+// only the reviews up to the end of the page for each car (MongoDB 5.2+),
+// so popular cars do not gather all of theirs in one group.
+func (r *MongoReviewRepository) GetReviewsByCarIDs(ctx context.Context, carIDs []primitive.ObjectID, params model.ReviewListParams) (map[primitive.ObjectID][]model.Review, error) {
+	params = normalizeReviewListParams(params)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"car_id": bson.M{"$in": carIDs}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": "$car_id",
+			"reviews": bson.M{"$topN": bson.M{
+				"n":      params.Page * params.Limit,
+				"sortBy": reviewSortOrder(params.Sort),
+				"output": "$$ROOT",
+			}},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"reviews": bson.M{"$slice": bson.A{"$reviews", (params.Page - 1) * params.Limit, params.Limit}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		CarID   primitive.ObjectID `bson:"_id"`
+		Reviews []model.Review     `bson:"reviews"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	reviews := make(map[primitive.ObjectID][]model.Review, len(groups))
+	for _, g := range groups {
+		reviews[g.CarID] = g.Reviews
+	}
+	return reviews, nil
+}
+
+// GetRatingStats computes the average rating and per-star histogram for a car
+func (r *MongoReviewRepository) GetRatingStats(ctx context.Context, carID primitive.ObjectID) (*model.RatingStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"car_id": carID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$rating",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var buckets []struct {
+		Rating int `bson:"_id"`
+		Count  int `bson:"count"`
+	}
+	if err = cursor.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+
+	stats := &model.RatingStats{Distribution: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}}
 	var totalRating int
-	for _, review := range reviews {
-		totalRating += review.Rating
+	for _, b := range buckets {
+		stats.Distribution[b.Rating] = b.Count
+		stats.TotalReviews += b.Count
+		totalRating += b.Rating * b.Count
 	}
 
-	averageRating := 0.0
-	if len(reviews) > 0 {
-		averageRating = float64(totalRating) / float64(len(reviews))
+	if stats.TotalReviews > 0 {
+		stats.AverageRating = float64(totalRating) / float64(stats.TotalReviews)
 	}
 
-	return &model.ReviewsResponse{
-		Reviews:       reviews,
-		AverageRating: averageRating,
-		TotalReviews:  len(reviews),
-	}, nil
+	return stats, nil
 }
 
 // UpdateReview updates an existing review (only by the owner)
-func (r *MongoReviewRepository) UpdateReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, input model.UpdateReviewInput) error {
-	filter := bson.M{
-		"_id":     reviewID,
-		"user_id": userID, // Ensure user owns the review
-	}
+func (r *MongoReviewRepository) UpdateReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, input model.UpdateReviewInput, version int64) (*model.Review, error) {
+	filter := versionFilter(reviewID, version)
+	filter["user_id"] = userID // Ensure user owns the review
 
 	update := bson.M{
 		"$set": bson.M{
@@ -92,6 +194,180 @@ func (r *MongoReviewRepository) UpdateReview(ctx context.Context, reviewID primi
 			"comment":    input.Comment,
 			"updated_at": time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	var review model.Review
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return nil, r.reviewVersionMismatch(ctx, reviewID, userID, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.refreshCarRating(ctx, review.CarID); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// DeleteReview deletes a review (only by the owner)
+func (r *MongoReviewRepository) DeleteReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, version int64) error {
+	filter := versionFilter(reviewID, version)
+	filter["user_id"] = userID // Ensure user owns the review
+
+	var review model.Review
+	err := r.collection.FindOneAndDelete(ctx, filter).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return r.reviewVersionMismatch(ctx, reviewID, userID, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.votesCollection.DeleteMany(ctx, bson.M{"review_id": reviewID}); err != nil {
+		slog.ErrorContext(ctx, "review deleted but its votes were not", "review_id", reviewID.Hex(), "error", err)
+		return err
+	}
+
+	return r.refreshCarRating(ctx, review.CarID)
+}
+
+// reviewVersionMismatch reports why an owner's versioned write matched nothing.
+// Reviews owned by someone else count as not found.
+func (r *MongoReviewRepository) reviewVersionMismatch(ctx context.Context, reviewID, userID primitive.ObjectID, version int64) error {
+	review, err := r.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if review.UserID != userID || version == AnyVersion {
+		return domain.NotFound("review")
+	}
+	return domain.PreconditionFailed("review")
+}
+
+// GetReviewByID returns a review by ID
+func (r *MongoReviewRepository) GetReviewByID(ctx context.Context, reviewID primitive.ObjectID) (*model.Review, error) {
+	var review model.Review
+	err := r.collection.FindOne(ctx, bson.M{"_id": reviewID}).Decode(&review)
+	if err != nil {
+		return nil, mongoError(err, "review")
+	}
+	return &review, nil
+}
+
+// VoteReview records a helpful/unhelpful vote. A user has at most one vote per review:
+// repeating the same vote removes it and the opposite vote replaces it.
+func (r *MongoReviewRepository) VoteReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID, helpful bool) (*model.Review, error) {
+	voteFilter := bson.M{
+		"review_id": reviewID,
+		"user_id":   userID,
+	}
+
+	var existing model.ReviewVote
+	err := r.votesCollection.FindOne(ctx, voteFilter).Decode(&existing)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	switch {
+	case err == mongo.ErrNoDocuments:
+		// First vote
+		vote := model.ReviewVote{
+			ReviewID:  reviewID,
+			UserID:    userID,
+			Helpful:   helpful,
+			CreatedAt: time.Now(),
+		}
+		if _, err := r.votesCollection.InsertOne(ctx, vote); err != nil {
+			// The unique index rejects an identical vote that raced this one
+			return nil, mongoError(err, "vote")
+		}
+	case existing.Helpful == helpful:
+		// Same vote again: toggle it off
+		if _, err := r.votesCollection.DeleteOne(ctx, bson.M{"_id": existing.ID}); err != nil {
+			return nil, err
+		}
+	default:
+		// Opposite vote: switch it
+		update := bson.M{"$set": bson.M{"helpful": helpful, "created_at": time.Now()}}
+		if _, err := r.votesCollection.UpdateOne(ctx, bson.M{"_id": existing.ID}, update); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.refreshVoteCounts(ctx, reviewID)
+}
+
+// refreshVoteCounts recomputes a review's vote counters from review_votes, so
+// a counter update that failed or raced another vote is corrected by the next one
+func (r *MongoReviewRepository) refreshVoteCounts(ctx context.Context, reviewID primitive.ObjectID) (*model.Review, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"review_id": reviewID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$helpful",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.votesCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Helpful bool `bson:"_id"`
+		Count   int  `bson:"count"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	var helpfulCount, unhelpfulCount int
+	for _, g := range groups {
+		if g.Helpful {
+			helpfulCount = g.Count
+		} else {
+			unhelpfulCount = g.Count
+		}
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"helpful_count":   helpfulCount,
+			"unhelpful_count": unhelpfulCount,
+			"helpful_score":   helpfulCount - unhelpfulCount,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var review model.Review
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": reviewID}, update, opts).Decode(&review)
+	if err != nil {
+		// The vote is saved; the counts catch up with the review's next vote
+		slog.ErrorContext(ctx, "vote saved but review counts not updated", "review_id", reviewID.Hex(), "error", err)
+		return nil, mongoError(err, "review")
+	}
+
+	return &review, nil
+}
+
+// ReplyToReview attaches the single public reply to a review
+func (r *MongoReviewRepository) ReplyToReview(ctx context.Context, reviewID primitive.ObjectID, reply *model.ReviewReply) error {
+	reply.CreatedAt = time.Now()
+
+	filter := bson.M{
+		"_id":   reviewID,
+		"reply": bson.M{"$exists": false}, // Only one reply per review
+	}
+
+	update := bson.M{
+		"$set": bson.M{"reply": reply},
+		"$inc": bson.M{"version": 1},
 	}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
@@ -100,37 +376,90 @@ func (r *MongoReviewRepository) UpdateReview(ctx context.Context, reviewID primi
 	}
 
 	if result.MatchedCount == 0 {
-		return mongo.ErrNoDocuments
+		if _, err := r.GetReviewByID(ctx, reviewID); err != nil {
+			return err
+		}
+		return ErrReviewAlreadyReplied
 	}
 
 	return nil
 }
 
-// DeleteReview deletes a review (only by the owner)
-func (r *MongoReviewRepository) DeleteReview(ctx context.Context, reviewID primitive.ObjectID, userID primitive.ObjectID) error {
+// DeleteReply removes the reply from a review
+func (r *MongoReviewRepository) DeleteReply(ctx context.Context, reviewID primitive.ObjectID) error {
 	filter := bson.M{
-		"_id":     reviewID,
-		"user_id": userID, // Ensure user owns the review
+		"_id":   reviewID,
+		"reply": bson.M{"$exists": true},
 	}
 
-	result, err := r.collection.DeleteOne(ctx, filter)
+	update := bson.M{
+		"$unset": bson.M{"reply": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return mongo.ErrNoDocuments
+	if result.MatchedCount == 0 {
+		return domain.NotFound("reply")
 	}
 
 	return nil
 }
 
-// GetReviewByID returns a review by ID
-func (r *MongoReviewRepository) GetReviewByID(ctx context.Context, reviewID primitive.ObjectID) (*model.Review, error) {
-	var review model.Review
-	err := r.collection.FindOne(ctx, bson.M{"_id": reviewID}).Decode(&review)
+// refreshCarRating recomputes the denormalized rating_avg/rating_count on the car
+func (r *MongoReviewRepository) refreshCarRating(ctx context.Context, carID primitive.ObjectID) error {
+	stats, err := r.GetRatingStats(ctx, carID)
 	if err != nil {
-		return nil, err
+		slog.ErrorContext(ctx, "compute car rating", "car_id", carID.Hex(), "error", err)
+		return err
 	}
-	return &review, nil
+
+	update := bson.M{
+		"$set": bson.M{
+			"rating_avg":   stats.AverageRating,
+			"rating_count": stats.TotalReviews,
+		},
+		"$inc": bson.M{"version": 1}, // the representation changed, so cached ETags must not match
+	}
+
+	if _, err := r.carsCollection.UpdateOne(ctx, bson.M{"_id": carID}, update); err != nil {
+		slog.ErrorContext(ctx, "save car rating", "car_id", carID.Hex(), "error", err)
+		return err
+	}
+	return nil
+}
+
+// normalizeReviewListParams fills in defaults and clamps the page size
+func normalizeReviewListParams(params model.ReviewListParams) model.ReviewListParams {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = defaultReviewsLimit
+	}
+	if params.Limit > maxReviewsLimit {
+		params.Limit = maxReviewsLimit
+	}
+	if !model.ValidReviewSort(params.Sort) {
+		params.Sort = model.ReviewSortNewest
+	}
+	return params
+}
+
+// reviewSortOrder maps a review sort option to a Mongo sort document
+func reviewSortOrder(sort string) bson.D {
+	switch sort {
+	case model.ReviewSortOldest:
+		return bson.D{{Key: "created_at", Value: 1}}
+	case model.ReviewSortHighest:
+		return bson.D{{Key: "rating", Value: -1}, {Key: "created_at", Value: -1}}
+	case model.ReviewSortLowest:
+		return bson.D{{Key: "rating", Value: 1}, {Key: "created_at", Value: -1}}
+	case model.ReviewSortMostHelpful:
+		return bson.D{{Key: "helpful_score", Value: -1}, {Key: "created_at", Value: -1}}
+	}
+	return bson.D{{Key: "created_at", Value: -1}}
 }
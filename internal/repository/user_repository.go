@@ -2,9 +2,9 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"github.com/teamserik/online-car-store/internal/domain"
 	"github.com/teamserik/online-car-store/internal/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -18,6 +18,8 @@ type UserRepository interface {
 	FindByUsername(ctx context.Context, username string) (*model.User, error)
 	Update(ctx context.Context, id primitive.ObjectID, user *model.User) error
 	GetUserByID(ctx context.Context, id primitive.ObjectID) (*model.User, error)
+	// FindByIDs returns the users that exist among ids, in no particular order
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.User, error)
 }
 
 type MongoUserRepository struct {
@@ -40,17 +42,14 @@ func (r *MongoUserRepository) Create(ctx context.Context, user *model.User) erro
 	}
 
 	_, err := r.collection.InsertOne(ctx, user)
-	return err
+	return mongoError(err, "user")
 }
 
 func (r *MongoUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
 	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
+		return nil, mongoError(err, "user")
 	}
 	return &user, nil
 }
@@ -59,10 +58,7 @@ func (r *MongoUserRepository) FindByID(ctx context.Context, id primitive.ObjectI
 	var user model.User
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
+		return nil, mongoError(err, "user")
 	}
 	return &user, nil
 }
@@ -71,10 +67,7 @@ func (r *MongoUserRepository) FindByUsername(ctx context.Context, username strin
 	var user model.User
 	err := r.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
+		return nil, mongoError(err, "user")
 	}
 	return &user, nil
 }
@@ -96,11 +89,11 @@ func (r *MongoUserRepository) Update(ctx context.Context, id primitive.ObjectID,
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return err
+		return mongoError(err, "user")
 	}
 
 	if result.MatchedCount == 0 {
-		return errors.New("user not found")
+		return domain.NotFound("user")
 	}
 
 	return nil
@@ -110,3 +103,17 @@ func (r *MongoUserRepository) Update(ctx context.Context, id primitive.ObjectID,
 func (r *MongoUserRepository) GetUserByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
 	return r.FindByID(ctx, id)
 }
+
+func (r *MongoUserRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*model.User, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []*model.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,173 @@
+// Package router registers routes on an http.ServeMux using method-and-wildcard
+// patterns, groups them under shared prefixes and middleware, and answers
+// requests for a known path with the wrong method with 405 and an Allow header.
+package router
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/problem"
+)
+
+// Middleware wraps a single route's handler
+type Middleware func(next http.HandlerFunc) http.HandlerFunc
+
+// Route is a registered method and ServeMux pattern
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Pattern is the ServeMux pattern the route is registered under, e.g. "GET /api/cars/{id}"
+func (r Route) Pattern() string {
+	return r.Method + " " + r.Path
+}
+
+// methods are probed to build the Allow header
+var methods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// table is shared by a router and the groups made from it
+type table struct {
+	mux          *http.ServeMux
+	routes       []Route
+	fallback     string
+	replacements map[string]http.HandlerFunc
+}
+
+// Router registers routes under a path prefix with middleware applied to each
+type Router struct {
+	table      *table
+	prefix     string
+	middleware []Middleware
+}
+
+// New returns a router over a new ServeMux
+func New() *Router {
+	return &Router{table: &table{mux: http.NewServeMux(), replacements: map[string]http.HandlerFunc{}}}
+}
+
+// Group returns a router that registers under prefix, relative to r's, and
+// wraps handlers in mw inside r's middleware
+func (r *Router) Group(prefix string, mw ...Middleware) *Router {
+	return &Router{
+		table:      r.table,
+		prefix:     r.prefix + prefix,
+		middleware: append(slices.Clip(r.middleware), mw...),
+	}
+}
+
+// With returns a router with the same prefix and mw added to its middleware
+func (r *Router) With(mw ...Middleware) *Router {
+	return r.Group("", mw...)
+}
+
+// Handle registers h for method and path, which may contain wildcards such as {id}
+func (r *Router) Handle(method, path string, h http.HandlerFunc) {
+	route := Route{Method: method, Path: r.prefix + path}
+	if replacement, ok := r.table.replacements[route.Pattern()]; ok {
+		h = replacement
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	r.table.mux.HandleFunc(route.Pattern(), h)
+	r.table.routes = append(r.table.routes, route)
+}
+
+// Get registers a GET route, which also answers HEAD
+func (r *Router) Get(path string, h http.HandlerFunc) { r.Handle(http.MethodGet, path, h) }
+
+// Post registers a POST route
+func (r *Router) Post(path string, h http.HandlerFunc) { r.Handle(http.MethodPost, path, h) }
+
+// Put registers a PUT route
+func (r *Router) Put(path string, h http.HandlerFunc) { r.Handle(http.MethodPut, path, h) }
+
+// Patch registers a PATCH route
+func (r *Router) Patch(path string, h http.HandlerFunc) { r.Handle(http.MethodPatch, path, h) }
+
+// Delete registers a DELETE route
+func (r *Router) Delete(path string, h http.HandlerFunc) { r.Handle(http.MethodDelete, path, h) }
+
+// Replace makes a later Handle for method and path register h instead of its
+// own handler, keeping the middleware of the group that registers it. This
+// lets an API version reuse shared registrations and change single routes.
+func (r *Router) Replace(method, path string, h http.HandlerFunc) {
+	r.table.replacements[Route{Method: method, Path: r.prefix + path}.Pattern()] = h
+}
+
+// Fallback serves GET requests that match no route, such as the static UI.
+// Unlike a route it does not make a path count as known, so other methods on
+// an unknown path get 404 rather than 405.
+func (r *Router) Fallback(h http.Handler) {
+	r.table.fallback = "GET /"
+	r.table.mux.Handle(r.table.fallback, h)
+}
+
+// Routes lists the registered routes in registration order
+func (r *Router) Routes() []Route {
+	return slices.Clone(r.table.routes)
+}
+
+// Mux is the underlying ServeMux, for middleware that looks up the route of a request
+func (r *Router) Mux() *http.ServeMux {
+	return r.table.mux
+}
+
+// ServeHTTP dispatches to the matching route, or to the fallback when the path
+// has no routes at all. Other requests get a problem response: 405 with Allow
+// when the path exists for other methods.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h, pattern := r.table.mux.Handler(req)
+	if pattern != "" && pattern != r.table.fallback {
+		r.table.mux.ServeHTTP(w, req)
+		return
+	}
+
+	allowed, sawFallback := r.allowed(req)
+	switch {
+	case pattern != "" && len(allowed) == 0:
+		r.table.mux.ServeHTTP(w, req)
+	case len(allowed) > 0:
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		problem.Write(w, req, http.StatusMethodNotAllowed, "Method not allowed")
+	case sawFallback:
+		problem.Write(w, req, http.StatusNotFound, "Not found")
+	default:
+		// Not found, or a redirect to the cleaned path
+		h.ServeHTTP(w, req)
+	}
+}
+
+// allowed lists the methods routed for req's path, not counting the fallback
+func (r *Router) allowed(req *http.Request) (allowed []string, sawFallback bool) {
+	for _, method := range methods {
+		probe := req.Clone(req.Context())
+		probe.Method = method
+		_, pattern := r.table.mux.Handler(probe)
+		switch {
+		case pattern == "":
+		case pattern == r.table.fallback:
+			sawFallback = true
+		default:
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed, sawFallback
+}
+
+// Switch dispatches on the value of the path wildcard name, answering 404 for
+// values without a handler. It lets two literal segments share a pattern where
+// separate patterns would collide with another route.
+func Switch(name string, handlers map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h, ok := handlers[r.PathValue(name)]
+		if !ok {
+			problem.Write(w, r, http.StatusNotFound, "Not found")
+			return
+		}
+		h(w, r)
+	}
+}
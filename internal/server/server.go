@@ -0,0 +1,191 @@
+// Package server runs the HTTP listener together with background workers and
+// shuts both down in order when the process is asked to stop.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/teamserik/online-car-store/internal/config"
+)
+
+// Worker is a background job that runs until its context is cancelled
+type Worker func(ctx context.Context)
+
+// WorkerStatus is the state of a background worker
+type WorkerStatus struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	StoppedAt time.Time `json:"stopped_at,omitempty"`
+}
+
+// Server is an http.Server configured from config.Server plus its workers
+type Server struct {
+	cfg     config.Server
+	http    *http.Server
+	certs   *certReloader
+	workers map[string]Worker
+
+	mu     sync.Mutex
+	status map[string]*WorkerStatus
+
+	onShutdown []func()
+}
+
+// New builds a Server for handler. When TLS is configured the certificate
+// is loaded now, so a bad key pair fails startup rather than the first request.
+func New(cfg config.Server, handler http.Handler) (*Server, error) {
+	s := &Server{
+		cfg: cfg,
+		http: &http.Server{
+			Addr:              ":" + cfg.Port,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		},
+		workers: map[string]Worker{},
+		status:  map[string]*WorkerStatus{},
+	}
+
+	if cfg.TLS.Enabled() {
+		certs, err := newCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.certs = certs
+		s.http.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+		s.Go("tls-reload", func(ctx context.Context) { certs.Watch(ctx, cfg.TLS.ReloadInterval) })
+	}
+
+	return s, nil
+}
+
+// Addr is the address the server listens on
+func (s *Server) Addr() string {
+	return s.http.Addr
+}
+
+// TLS reports whether the server serves HTTPS
+func (s *Server) TLS() bool {
+	return s.certs != nil
+}
+
+// TLSConfig is the HTTPS configuration, sharing the reloaded certificate, or
+// nil when TLS is off. Other listeners such as gRPC use it to serve the same certificate.
+func (s *Server) TLSConfig() *tls.Config {
+	return s.http.TLSConfig
+}
+
+// Go registers a background worker, started by Run and stopped after the
+// listener has drained. It must be called before Run.
+func (s *Server) Go(name string, worker Worker) {
+	if _, ok := s.workers[name]; ok {
+		panic("server: duplicate worker " + name)
+	}
+	s.workers[name] = worker
+	s.status[name] = &WorkerStatus{Name: name}
+}
+
+// Workers reports the state of every registered worker, sorted by name
+func (s *Server) Workers() []WorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]WorkerStatus, 0, len(s.status))
+	for _, status := range s.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (s *Server) setRunning(name string, running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.status[name]
+	status.Running = running
+	if running {
+		status.StartedAt = time.Now()
+	} else {
+		status.StoppedAt = time.Now()
+	}
+}
+
+// OnShutdown registers fn to be called as soon as shutdown begins, before the
+// shutdown delay and the drain. It must be called before Run.
+func (s *Server) OnShutdown(fn func()) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// Run serves until ctx is cancelled, then stops accepting connections, waits up
+// to the shutdown timeout for in-flight requests and stops the workers.
+// It returns the listener's error if serving failed, or the drain error.
+func (s *Server) Run(ctx context.Context) error {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for name, worker := range s.workers {
+		wg.Add(1)
+		s.setRunning(name, true)
+		go func() {
+			defer wg.Done()
+			worker(workerCtx)
+			s.setRunning(name, false)
+			slog.Info("worker stopped", "worker", name)
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if s.certs != nil {
+			// Certificates come from TLSConfig.GetCertificate
+			serveErr <- s.http.ListenAndServeTLS("", "")
+		} else {
+			serveErr <- s.http.ListenAndServe()
+		}
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		err = fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+		slog.Info("shutting down", "drain_timeout", s.cfg.ShutdownTimeout, "delay", s.cfg.ShutdownDelay)
+		for _, fn := range s.onShutdown {
+			fn()
+		}
+		if s.cfg.ShutdownDelay > 0 {
+			time.Sleep(s.cfg.ShutdownDelay)
+		}
+
+		drainCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+		defer cancel()
+		if shutdownErr := s.http.Shutdown(drainCtx); shutdownErr != nil {
+			err = fmt.Errorf("drain requests: %w", shutdownErr)
+			s.http.Close()
+		}
+		if serveErr := <-serveErr; !errors.Is(serveErr, http.ErrServerClosed) && err == nil {
+			err = fmt.Errorf("serve: %w", serveErr)
+		}
+	}
+
+	stopWorkers()
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,60 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+)
+
+// MaxBodyBytes caps the size of JSON request bodies
+const MaxBodyBytes = 1 << 20
+
+// Decode reads a single JSON object from the request body into dst and validates it.
+// Unknown fields, trailing data and bodies over MaxBodyBytes are rejected.
+// Errors are domain errors ready for problem.Error.
+func Decode(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return domain.NewValidationError("body", "must contain a single JSON object")
+	}
+
+	return Struct(dst)
+}
+
+// decodeError turns encoding/json errors into field-level validation errors
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return domain.TooLarge(fmt.Sprintf("request body must not exceed %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, io.EOF):
+		return domain.NewValidationError("body", "must not be empty")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return domain.NewValidationError("body", "must be valid JSON")
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return domain.NewValidationError("body", "must be a JSON object")
+		}
+		return domain.NewValidationError(typeErr.Field, "must be of type "+typeErr.Type.String())
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return domain.NewValidationError(field, "is not a known field")
+	}
+	return domain.NewValidationError("body", "could not be decoded")
+}
@@ -0,0 +1,194 @@
+// Package validate checks input models against rules declared in `validate`
+// struct tags and decodes request bodies strictly.
+//
+// Rules are comma separated and applied in order:
+//
+//	required      value must not be empty (strings are trimmed first)
+//	omitempty     skip the remaining rules when the value is empty
+//	min=N, max=N  numeric bounds; length bounds for strings and slices
+//	maxbytes=N    string must be at most N bytes long, whatever its characters
+//	oneof=A B C   value must be one of the listed words
+//	email         value must be a plain e-mail address
+//	url           value must be an absolute http(s) URL
+//	objectid      value must be a hex ObjectID
+//	notfuture     year must not be after the current year
+//
+// Nil pointers are skipped, so optional fields only get checked when sent.
+// Nested structs and slices of structs are validated too.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Struct validates v, which must be a struct or a pointer to one.
+// It returns a *domain.ValidationError listing every invalid field, or nil.
+func Struct(v any) error {
+	errs := &domain.ValidationError{}
+	checkStruct(reflect.Indirect(reflect.ValueOf(v)), "", errs)
+	return errs.Err()
+}
+
+func checkStruct(v reflect.Value, prefix string, errs *domain.ValidationError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + fieldName(field)
+		checkValue(v.Field(i), name, field.Tag.Get("validate"), errs)
+	}
+}
+
+func checkValue(v reflect.Value, name, rules string, errs *domain.ValidationError) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if rules != "" {
+		if msg := applyRules(v, rules); msg != "" {
+			errs.Add(name, msg)
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() != reflect.TypeOf(time.Time{}) && v.Type() != reflect.TypeOf(primitive.ObjectID{}) {
+			checkStruct(v, name+".", errs)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			elem := reflect.Indirect(v.Index(i))
+			if elem.Kind() == reflect.Struct {
+				checkStruct(elem, fmt.Sprintf("%s[%d].", name, i), errs)
+			}
+		}
+	}
+}
+
+// applyRules returns the message for the first rule v breaks, or ""
+func applyRules(v reflect.Value, rules string) string {
+	for _, rule := range strings.Split(rules, ",") {
+		key, arg, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			if isEmpty(v) {
+				return "is required"
+			}
+		case "omitempty":
+			if isEmpty(v) {
+				return ""
+			}
+		case "min":
+			if msg := checkBound(v, arg, true); msg != "" {
+				return msg
+			}
+		case "max":
+			if msg := checkBound(v, arg, false); msg != "" {
+				return msg
+			}
+		case "maxbytes":
+			limit, err := strconv.Atoi(arg)
+			if err != nil {
+				panic("validate: bad bound " + strconv.Quote(arg))
+			}
+			if len(v.String()) > limit {
+				return "must be at most " + arg + " bytes"
+			}
+		case "oneof":
+			options := strings.Fields(arg)
+			if !contains(options, v.String()) {
+				return "must be one of: " + strings.Join(options, ", ")
+			}
+		case "email":
+			if addr, err := mail.ParseAddress(v.String()); err != nil || addr.Address != v.String() {
+				return "must be a valid email address"
+			}
+		case "url":
+			if u, err := url.Parse(v.String()); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return "must be a valid http(s) URL"
+			}
+		case "objectid":
+			if !primitive.IsValidObjectID(v.String()) {
+				return "must be a valid ID"
+			}
+		case "notfuture":
+			if v.Int() > int64(time.Now().Year()) {
+				return "must not be in the future"
+			}
+		default:
+			panic("validate: unknown rule " + strconv.Quote(key))
+		}
+	}
+	return ""
+}
+
+func checkBound(v reflect.Value, arg string, isMin bool) string {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic("validate: bad bound " + strconv.Quote(arg))
+	}
+
+	var n float64
+	unit := ""
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice:
+		n, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		panic("validate: min/max on unsupported kind " + v.Kind().String())
+	}
+
+	switch {
+	case isMin && n < bound:
+		return "must be at least " + arg + unit
+	case !isMin && n > bound:
+		return "must be at most " + arg + unit
+	}
+	return ""
+}
+
+func isEmpty(v reflect.Value) bool {
+	if v.Kind() == reflect.String {
+		return strings.TrimSpace(v.String()) == ""
+	}
+	return v.IsZero()
+}
+
+// fieldName returns the JSON name of field, so errors match what the client sent
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func contains(options []string, s string) bool {
+	for _, option := range options {
+		if option == s {
+			return true
+		}
+	}
+	return false
+}
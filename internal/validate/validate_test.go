@@ -0,0 +1,36 @@
+package validate_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/teamserik/online-car-store/internal/domain"
+	"github.com/teamserik/online-car-store/internal/model"
+	"github.com/teamserik/online-car-store/internal/validate"
+)
+
+func TestPasswordByteLimit(t *testing.T) {
+	input := model.RegisterInput{Username: "ivan", Email: "ivan@example.com"}
+
+	for _, tc := range []struct {
+		password string
+		valid    bool
+	}{
+		{strings.Repeat("a", 72), true},
+		{strings.Repeat("a", 73), false},
+		{strings.Repeat("п", 36), true},  // 72 bytes
+		{strings.Repeat("п", 40), false}, // 40 characters but 80 bytes
+	} {
+		input.Password = tc.password
+		err := validate.Struct(&input)
+
+		var verr *domain.ValidationError
+		if tc.valid && err != nil {
+			t.Errorf("%d-byte password: %v, want valid", len(tc.password), err)
+		}
+		if !tc.valid && (!errors.As(err, &verr) || !strings.Contains(err.Error(), "password")) {
+			t.Errorf("%d-byte password: %v, want a password validation error", len(tc.password), err)
+		}
+	}
+}
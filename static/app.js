@@ -1,4 +1,4 @@
-const API_URL = 'http://localhost:3000/api';
+const API_URL = 'http://localhost:3000/api/v1';
 
 // State
 let allCars = [];
@@ -42,6 +42,20 @@ function handleAuthError(response) {
     return false;
 }
 
+// Errors come back as application/problem+json; fall back to plain text
+async function problemDetail(response) {
+    const text = await response.text();
+    try {
+        const problem = JSON.parse(text);
+        if (problem.errors) {
+            return problem.errors.map(e => `${e.field}: ${e.message}`).join(', ');
+        }
+        return problem.detail || problem.title || text;
+    } catch {
+        return text;
+    }
+}
+
 function updateAuthUI() {
     const token = localStorage.getItem('token');
     const user = JSON.parse(localStorage.getItem('user') || 'null');
@@ -200,7 +214,8 @@ async function loadFavoritesPage() {
         if (handleAuthError(response)) return;
 
         if (response.ok) {
-            favorites = await response.json();
+            const data = await response.json();
+            favorites = data.favorites;
             displayFavorites();
         } else {
             container.innerHTML = '<div class="error">Failed to load favorites</div>';
@@ -258,7 +273,7 @@ function displayReviews(data, carId) {
                 ${data.average_rating ? `
                     <span class="rating-stars">${'⭐'.repeat(Math.round(data.average_rating))}</span>
                     <span class="rating-value">${data.average_rating.toFixed(1)} / 5</span>
-                    <span class="review-count">(${data.reviews.length} reviews)</span>
+                    <span class="review-count">(${data.total_reviews} reviews)</span>
                 ` : '<span>No reviews yet</span>'}
             </div>
         </div>
@@ -302,11 +317,23 @@ function displayReviews(data, carId) {
                         <span class="review-rating">${'⭐'.repeat(review.rating)}</span>
                     </div>
                     <p class="review-comment">${review.comment}</p>
+                    ${review.reply ? `
+                        <div class="review-reply">
+                            <span class="review-author">${review.reply.username} (${review.reply.role === 'admin' ? 'admin' : 'dealer'})</span>
+                            <p>${review.reply.comment}</p>
+                        </div>
+                    ` : ''}
                     <div class="review-footer">
                         <span class="review-date">${new Date(review.created_at).toLocaleDateString()}</span>
+                        ${token && !isOwnReview ? `
+                            <div class="review-votes">
+                                <button class="btn btn-sm btn-secondary" onclick="voteReview('${review.id}', true, '${carId}')">👍 ${review.helpful_count || 0}</button>
+                                <button class="btn btn-sm btn-secondary" onclick="voteReview('${review.id}', false, '${carId}')">👎 ${review.unhelpful_count || 0}</button>
+                            </div>
+                        ` : ''}
                         ${isOwnReview ? `
                             <div class="review-actions">
-                                <button class="btn btn-sm btn-secondary" onclick="editReview('${review.id}', '${review.comment}', ${review.rating})">Edit</button>
+                                <button class="btn btn-sm btn-secondary" onclick="editReview('${review.id}', '${review.comment}', ${review.rating}, ${review.version})">Edit</button>
                                 <button class="btn btn-sm btn-danger" onclick="deleteReview('${review.id}')">Delete</button>
                             </div>
                         ` : ''}
@@ -344,8 +371,7 @@ async function submitReview(event, carId) {
         if (response.ok) {
             showReviewsModal(carId);
         } else {
-            const error = await response.text();
-            alert(`Error: ${error}`);
+            alert(`Error: ${await problemDetail(response)}`);
         }
     } catch (error) {
         console.error('Error submitting review:', error);
@@ -353,6 +379,29 @@ async function submitReview(event, carId) {
     }
 }
 
+async function voteReview(reviewId, helpful, carId) {
+    if (!checkAuth()) return;
+
+    try {
+        const response = await fetch(`${API_URL}/reviews/${reviewId}/vote`, {
+            method: 'POST',
+            headers: getAuthHeaders(),
+            body: JSON.stringify({ helpful })
+        });
+
+        if (handleAuthError(response)) return;
+
+        if (response.ok) {
+            showReviewsModal(carId);
+        } else {
+            alert(`Error: ${await problemDetail(response)}`);
+        }
+    } catch (error) {
+        console.error('Error voting on review:', error);
+        alert('Failed to vote on review');
+    }
+}
+
 async function deleteReview(reviewId) {
     if (!confirm('Are you sure you want to delete this review?')) return;
 
@@ -382,13 +431,13 @@ async function deleteReview(reviewId) {
     }
 }
 
-function editReview(reviewId, currentComment, currentRating) {
+function editReview(reviewId, currentComment, currentRating, version) {
     const reviewItem = document.querySelector(`[data-review-id="${reviewId}"]`);
     const commentElement = reviewItem.querySelector('.review-comment');
     const actionsElement = reviewItem.querySelector('.review-actions');
 
     const editForm = `
-        <form class="edit-review-form" onsubmit="updateReview(event, '${reviewId}')">
+        <form class="edit-review-form" onsubmit="updateReview(event, '${reviewId}', ${version})">
             <div class="rating-input">
                 <label>Rating:</label>
                 <select name="rating" required>
@@ -411,7 +460,7 @@ function editReview(reviewId, currentComment, currentRating) {
     actionsElement.style.display = 'none';
 }
 
-async function updateReview(event, reviewId) {
+async function updateReview(event, reviewId, version) {
     event.preventDefault();
 
     const form = event.target;
@@ -420,9 +469,10 @@ async function updateReview(event, reviewId) {
     const comment = formData.get('comment');
 
     try {
+        // If-Match stops us from overwriting an edit made in another tab
         const response = await fetch(`${API_URL}/reviews/${reviewId}`, {
             method: 'PUT',
-            headers: getAuthHeaders(),
+            headers: { ...getAuthHeaders(), 'If-Match': `"${version}"` },
             body: JSON.stringify({ rating, comment })
         });
 
@@ -438,7 +488,7 @@ async function updateReview(event, reviewId) {
                 closeModal('reviews-modal');
             }
         } else {
-            alert('Failed to update review');
+            alert(`Failed to update review: ${await problemDetail(response)}`);
         }
     } catch (error) {
         console.error('Error updating review:', error);
@@ -505,19 +555,129 @@ function displayCars(cars) {
     container.innerHTML = cars.map(car => createCarCard(car)).join('');
 }
 
-function addToGarage(carId) {
-    const car = allCars.find(c => c.id === carId);
-    if (car && !garage.some(g => g.id === carId)) {
-        garage.push(car);
-        localStorage.setItem('garage', JSON.stringify(garage));
+// ============= GARAGE FUNCTIONS =============
+// Logged-in users keep their garage on the server; guests use localStorage.
+
+function toGarageEntry(item) {
+    return {
+        ...(item.car || {}),
+        id: item.car_id,
+        sold: item.sold,
+        price_changed: item.price_changed,
+        price_delta: item.price_delta,
+        price_when_added: item.price_when_added
+    };
+}
+
+async function loadGarage() {
+    const token = localStorage.getItem('token');
+    if (!token) {
         updateGarageCount();
-        displayCars(allCars);
+        return;
     }
+
+    try {
+        const localGarage = JSON.parse(localStorage.getItem('garage')) || [];
+        let response;
+
+        if (localGarage.length > 0) {
+            // Merge the garage from this browser into the account once
+            response = await fetch(`${API_URL}/garage/import`, {
+                method: 'POST',
+                headers: getAuthHeaders(),
+                body: JSON.stringify({
+                    cars: localGarage.map(car => ({ id: car.id, price: car.price }))
+                })
+            });
+
+            if (response.ok) {
+                const data = await response.json();
+                localStorage.removeItem('garage');
+                garage = data.garage.map(toGarageEntry);
+            }
+        } else {
+            response = await fetch(`${API_URL}/garage`, {
+                headers: getAuthHeaders()
+            });
+
+            if (response.ok) {
+                const items = await response.json();
+                garage = items.map(toGarageEntry);
+            }
+        }
+
+        if (response.status === 401) {
+            console.error('Token is invalid or expired');
+            return;
+        }
+    } catch (error) {
+        console.error('Error loading garage:', error);
+    }
+
+    updateGarageCount();
+    displayCars(allCars);
 }
 
-function removeFromGarage(carId) {
-    garage = garage.filter(car => car.id !== carId);
+async function addToGarage(carId) {
+    const car = allCars.find(c => c.id === carId);
+    if (!car || garage.some(g => g.id === carId)) return;
+
+    if (localStorage.getItem('token')) {
+        try {
+            const response = await fetch(`${API_URL}/garage`, {
+                method: 'POST',
+                headers: getAuthHeaders(),
+                body: JSON.stringify({ car_id: carId })
+            });
+
+            if (handleAuthError(response)) return;
+
+            if (!response.ok) {
+                alert('Failed to add to garage');
+                return;
+            }
+        } catch (error) {
+            console.error('Error adding to garage:', error);
+            alert('Error adding to garage');
+            return;
+        }
+
+        await loadGarage();
+        return;
+    }
+
+    garage.push(car);
     localStorage.setItem('garage', JSON.stringify(garage));
+    updateGarageCount();
+    displayCars(allCars);
+}
+
+async function removeFromGarage(carId) {
+    if (localStorage.getItem('token')) {
+        try {
+            const response = await fetch(`${API_URL}/garage/${carId}`, {
+                method: 'DELETE',
+                headers: getAuthHeaders()
+            });
+
+            if (handleAuthError(response)) return;
+
+            if (!response.ok) {
+                alert('Failed to remove from garage');
+                return;
+            }
+        } catch (error) {
+            console.error('Error removing from garage:', error);
+            alert('Error removing from garage');
+            return;
+        }
+
+        garage = garage.filter(car => car.id !== carId);
+    } else {
+        garage = garage.filter(car => car.id !== carId);
+        localStorage.setItem('garage', JSON.stringify(garage));
+    }
+
     updateGarageCount();
     displayGarage();
 }
@@ -534,13 +694,25 @@ function displayGarage() {
         return;
     }
 
-    container.innerHTML = garage.map(car => `
+    container.innerHTML = garage.map(car => car.sold ? `
+        <div class="car-card sold">
+            <div class="car-info">
+                <h3>No longer available</h3>
+                <p class="car-price">Was $${(car.price_when_added || 0).toLocaleString()}</p>
+                <span class="garage-badge">Sold</span>
+                <button class="btn btn-danger" onclick="removeFromGarage('${car.id}')">Remove</button>
+            </div>
+        </div>
+    ` : `
         <div class="car-card">
             <img src="${car.image_url || 'https://via.placeholder.com/300x200?text=No+Image'}" alt="${car.make} ${car.model}">
             <div class="car-info">
                 <h3>${car.make} ${car.model}</h3>
                 <p class="car-year">${car.year}</p>
                 <p class="car-price">$${car.price.toLocaleString()}</p>
+                ${car.price_changed ? `
+                    <span class="garage-badge">${car.price_delta < 0 ? 'Price dropped' : 'Price increased'} by $${Math.abs(car.price_delta).toLocaleString()}</span>
+                ` : ''}
                 <div class="car-specs">
                     <span>${car.mileage.toLocaleString()} km</span>
                     <span>${car.fuel_type}</span>
@@ -624,8 +796,7 @@ document.getElementById('add-car-form').addEventListener('submit', async (e) =>
             fetchCars();
             showPage('catalog-page');
         } else {
-            const error = await response.text();
-            alert(`Error: ${error}`);
+            alert(`Error: ${await problemDetail(response)}`);
         }
     } catch (error) {
         console.error('Error adding car:', error);
@@ -649,6 +820,7 @@ console.log('Initializing app...');
 updateAuthUI();
 updateGarageCount();
 fetchCars();
+loadGarage();
 
 // Store carId in modal when opening
 const originalShowReviewsModal = showReviewsModal;